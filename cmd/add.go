@@ -108,7 +108,12 @@ func runAdd(format csv.Format, inputPath string, addColumnName string, outputPat
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func add(reader csv.CsvReader, addColumnName string, writer csv.CsvWriter, options AddOptions) error {
@@ -119,6 +124,13 @@ func add(reader csv.CsvReader, addColumnName string, writer csv.CsvWriter, optio
 		return errors.Wrap(err, "failed to read the CSV file")
 	}
 
+	if options.template != nil {
+		// テンプレートにはカラム名をキーとしたmapで渡すので、重複したカラム名は扱えない
+		if err := csv.CheckDuplicateColumnNames(columnNames); err != nil {
+			return err
+		}
+	}
+
 	copyColumnIndex := -1
 	if options.copyColumnName != "" {
 		copyColumnIndex, err = getTargetColumnIndex(columnNames, options.copyColumnName)
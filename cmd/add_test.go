@@ -579,3 +579,73 @@ func TestAddCmd_templateExecuteError(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestAddCmd_template_duplicateHeader(t *testing.T) {
+
+	s := joinRows(
+		"col1,col1",
+		"1,a",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"add",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col3",
+		"--template", "{{.col1}}",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "duplicate column names in the header: col1 (use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestAddCmd_template_dedupeHeaders(t *testing.T) {
+
+	s := joinRows(
+		"col1,col1",
+		"1,a",
+		"2,b",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"add",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col3",
+		"--template", "{{.col1}}-{{.col1_2}}",
+		"--dedupe-headers",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col1_2,col3",
+		"1,a,1-a",
+		"2,b,2-b",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// 展開した入力ファイル
+type inputFile struct {
+	path string
+	// 展開元のディレクトリからの相対的なディレクトリ (出力ファイル名のテンプレートで使用)
+	dir string
+}
+
+// パターンもしくはディレクトリで、複数のファイルを指すか
+func isMultipleInput(inputPath string) bool {
+
+	if _, member, ok := csv.SplitArchivePath(inputPath); ok {
+		return csv.HasGlobPattern(member)
+	}
+
+	info, err := os.Stat(inputPath)
+	if err == nil {
+		return info.IsDir()
+	}
+
+	return csv.HasGlobPattern(inputPath)
+}
+
+// 入力ファイルのパスを展開する
+// パターン(アーカイブ内も含む)は一致したファイルに、ディレクトリは配下のファイルに展開する
+func expandInputPaths(inputPaths []string, format csv.Format, recursive bool) ([]string, error) {
+
+	expanded := []string{}
+	for _, inputPath := range inputPaths {
+
+		if !isMultipleInput(inputPath) {
+			expanded = append(expanded, inputPath)
+			continue
+		}
+
+		files, err := expandInputFiles(inputPath, format, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			expanded = append(expanded, file.path)
+		}
+	}
+
+	return expanded, nil
+}
+
+func expandInputFiles(inputPath string, format csv.Format, recursive bool) ([]inputFile, error) {
+
+	files := []inputFile{}
+
+	if archivePath, member, ok := csv.SplitArchivePath(inputPath); ok {
+
+		matched, err := csv.MatchArchiveMembers(archivePath, member, format.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matched {
+			dir, _ := filepath.Split(name)
+			files = append(files, inputFile{path: archivePath + csv.ArchiveSeparator + name, dir: dir})
+		}
+
+	} else if info, err := os.Stat(inputPath); err == nil && info.IsDir() {
+
+		dirFiles, err := listDirFiles(inputPath, "", recursive)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+
+	} else {
+
+		// 再帰的に探す場合は、最後の要素をファイル名のパターンとして配下のディレクトリも対象に
+		dirPattern, namePattern := filepath.Split(inputPath)
+		if dirPattern == "" {
+			dirPattern = "."
+		}
+
+		if recursive {
+			dirs, err := filepath.Glob(filepath.Clean(dirPattern))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern %s", inputPath)
+			}
+			for _, dir := range dirs {
+				if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					continue
+				}
+				dirFiles, err := listDirFiles(dir, namePattern, true)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, dirFiles...)
+			}
+		} else {
+			matched, err := filepath.Glob(inputPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern %s", inputPath)
+			}
+			for _, path := range matched {
+				if info, err := os.Stat(path); err == nil && !info.IsDir() {
+					files = append(files, inputFile{path: path})
+				}
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file matches %s", inputPath)
+	}
+
+	return files, nil
+}
+
+// ディレクトリ配下のファイルを返す (namePatternが指定された場合は、ファイル名が一致するもののみ)
+func listDirFiles(root string, namePattern string, recursive bool) ([]inputFile, error) {
+
+	files := []inputFile{}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if namePattern != "" {
+			matched, err := filepath.Match(namePattern, d.Name())
+			if err != nil {
+				return errors.Wrapf(err, "invalid pattern %s", namePattern)
+			}
+			if !matched {
+				return nil
+			}
+		}
+
+		dir, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if dir == "." {
+			dir = ""
+		}
+
+		files = append(files, inputFile{path: path, dir: dir})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// WalkDirはディレクトリ内を名前順に辿るので、並び替えは不要
+	return files, nil
+}
+
+// 出力ファイル名のテンプレートを適用
+// {dir}: 展開元からの相対的なディレクトリ, {name}: 拡張子を除いたファイル名, {ext}: 拡張子
+func makeBatchOutputPath(outputDir string, template string, file inputFile) string {
+
+	base := filepath.Base(filepath.FromSlash(file.path))
+	ext := filepath.Ext(base)
+
+	name := strings.NewReplacer(
+		"{dir}", filepath.ToSlash(file.dir),
+		"{name}", strings.TrimSuffix(base, ext),
+		"{ext}", ext,
+	).Replace(template)
+
+	return filepath.Join(outputDir, filepath.FromSlash(name))
+}
+
+// 全ての入力ファイルの出力先を求める
+// 出力先が重複する場合や、入力ファイルと同じになる場合はエラーとする
+func makeBatchOutputPaths(outputDir string, template string, files []inputFile) ([]string, error) {
+
+	inputs := map[string]string{}
+	for _, file := range files {
+		path := file.path
+		if archivePath, _, ok := csv.SplitArchivePath(path); ok {
+			path = archivePath
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		inputs[absPath] = file.path
+	}
+
+	outputPaths := []string{}
+	outputs := map[string]string{}
+	for _, file := range files {
+
+		outputPath := makeBatchOutputPath(outputDir, template, file)
+		absPath, err := filepath.Abs(outputPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if inputPath, ok := inputs[absPath]; ok {
+			return nil, fmt.Errorf("output file %s is the same as the input file %s (change the output directory or --output-name)", outputPath, inputPath)
+		}
+		if other, ok := outputs[absPath]; ok {
+			return nil, fmt.Errorf("output file %s is duplicated for %s and %s (change --output-name)", outputPath, other, file.path)
+		}
+		outputs[absPath] = file.path
+
+		outputPaths = append(outputPaths, outputPath)
+	}
+
+	return outputPaths, nil
+}
+
+// 入力ファイルにパターンやディレクトリが指定された場合に、ファイルごとに同じ処理を行うように
+func enableBatch(c *cobra.Command) {
+
+	inputFlag := c.Flags().Lookup("input")
+	if inputFlag == nil || inputFlag.Value.Type() != "string" {
+		// 複数ファイルを受け付けるコマンドは個別に対応
+		return
+	}
+
+	runE := c.RunE
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+
+		inputPath, _ := cmd.Flags().GetString("input")
+		if !isMultipleInput(inputPath) {
+			return runE(cmd, args)
+		}
+
+		if cmd.Flags().Lookup("output") == nil {
+			// 指定方法の誤りではないので、Usageは表示しない
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s does not support multiple input files", cmd.Name())
+		}
+		outputDir, _ := cmd.Flags().GetString("output")
+		if outputDir == "" {
+			return fmt.Errorf("flag output is required for multiple input files (specify the output directory)")
+		}
+
+		format, err := getFlagBaseCsvFormat(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		template, _ := cmd.Flags().GetString("output-name")
+
+		// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+		cmd.SilenceUsage = true
+
+		files, err := expandInputFiles(inputPath, format, recursive)
+		if err != nil {
+			return err
+		}
+
+		// 処理を始める前に、出力先が重複したり入力ファイルを上書きしないか確認
+		outputPaths, err := makeBatchOutputPaths(outputDir, template, files)
+		if err != nil {
+			return err
+		}
+
+		// 途中のファイルでエラーになっても残りのファイルは処理し、エラーはまとめて返す
+		messages := []string{}
+		for i, file := range files {
+
+			outputPath := outputPaths[i]
+			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+				return err
+			}
+
+			cmd.Flags().Set("input", file.path)
+			cmd.Flags().Set("output", outputPath)
+
+			if err := runE(cmd, args); err != nil {
+				messages = append(messages, errors.Wrapf(err, "failed to process %s", file.path).Error())
+			}
+		}
+
+		if len(messages) != 0 {
+			return errors.New(strings.Join(messages, "\n"))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatch_directory(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2,col3\r\n1,2,3\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.csv"), []byte("col1,col2,col3\r\n4,5,6\r\n"), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", dir,
+		"-c", "col3",
+		"--recursive",
+		"-o", outDir,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, filepath.Join(outDir, "a.csv"))
+	if result != joinRows("col3", "3") {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = readString(t, filepath.Join(outDir, "sub", "b.csv"))
+	if result != joinRows("col3", "6") {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestBatch_patternOutputName(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), []byte("col1,col2\r\n3,4\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "c.txt"), []byte("col1,col2\r\n5,6\r\n"), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", filepath.Join(dir, "*.csv"),
+		"--out-delim", "\t",
+		"--output-name", "{name}.tsv",
+		"-o", outDir,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	files := readDir(t, outDir)
+	if len(files) != 2 {
+		t.Fatal("failed test\n", files)
+	}
+
+	if string(files["a.tsv"]) != joinRows("col1\tcol2", "1\t2") {
+		t.Fatal("failed test\n", string(files["a.tsv"]))
+	}
+	if string(files["b.tsv"]) != joinRows("col1\tcol2", "3\t4") {
+		t.Fatal("failed test\n", string(files["b.tsv"]))
+	}
+}
+
+func TestBatch_noOutput(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"header",
+		"-i", dir,
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "header does not support multiple input files" {
+		t.Fatal("failed test\n", err)
+	}
+
+	// Usageは表示しない
+	if strings.Contains(buf.String(), "Usage:") {
+		t.Fatal("failed test\n", buf.String())
+	}
+}
+
+func TestBatch_duplicateOutput(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "a"), 0755)
+	os.MkdirAll(filepath.Join(dir, "b"), 0755)
+	os.WriteFile(filepath.Join(dir, "a", "x.csv"), []byte("id,name\r\n1,A\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b", "x.csv"), []byte("id,name\r\n2,B\r\n"), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", dir,
+		"-c", "id",
+		"--recursive",
+		"--output-name", "{name}{ext}",
+		"-o", outDir,
+	})
+
+	err := rootCmd.Execute()
+	expect := "output file " + filepath.Join(outDir, "x.csv") + " is duplicated for " + filepath.Join(dir, "a", "x.csv") + " and " + filepath.Join(dir, "b", "x.csv") + " (change --output-name)"
+	if err == nil || err.Error() != expect {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 処理を始める前にエラーとなる
+	files := readDir(t, outDir)
+	if len(files) != 0 {
+		t.Fatal("failed test\n", files)
+	}
+}
+
+func TestBatch_outputSameAsInput(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id,name\r\n1,A\r\n"), 0644)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", dir,
+		"-c", "id",
+		"-o", dir,
+	})
+
+	err := rootCmd.Execute()
+	expect := "output file " + filepath.Join(dir, "a.csv") + " is the same as the input file " + filepath.Join(dir, "a.csv") + " (change the output directory or --output-name)"
+	if err == nil || err.Error() != expect {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 入力ファイルは変わらない
+	result := readString(t, filepath.Join(dir, "a.csv"))
+	if result != joinRows("id,name", "1,A") {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestBatch_fileError(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), []byte("col2\r\n3\r\n"), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", dir,
+		"-c", "col1",
+		"-o", outDir,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to process "+filepath.Join(dir, "b.csv")+": missing col1 in the CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestBatch_fileErrors(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col2\r\n1\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), []byte("col1,col2\r\n2,3\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "c.csv"), []byte("col3\r\n4\r\n"), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", dir,
+		"-c", "col1",
+		"-o", outDir,
+	})
+
+	// エラーになったファイルがあっても、全てのファイルを処理する
+	err := rootCmd.Execute()
+	expect := "failed to process " + filepath.Join(dir, "a.csv") + ": missing col1 in the CSV file\n" +
+		"failed to process " + filepath.Join(dir, "c.csv") + ": missing col1 in the CSV file"
+	if err == nil || err.Error() != expect {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, filepath.Join(outDir, "b.csv"))
+	if result != joinRows("col1", "2") {
+		t.Fatal("failed test\n", result)
+	}
+}
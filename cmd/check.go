@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCmd() *cobra.Command {
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the structure of CSV file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runCheck(
+				format,
+				inputPath,
+				outputPath)
+		},
+	}
+
+	checkCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	checkCmd.MarkFlagRequired("input")
+	checkCmd.Flags().StringP("output", "o", "", "Output CSV file path for problems.")
+	checkCmd.MarkFlagRequired("output")
+
+	return checkCmd
+}
+
+func runCheck(format csv.Format, inputPath string, outputPath string) error {
+
+	input, inputClose, err := openInputFile(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer inputClose()
+
+	// 不正なレコードがあっても読み進められるように
+	scanner := csv.NewRecordScanner(input, format)
+
+	writer, close, err := setupOutput(outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	count, err := check(scanner, format.NoHeader, writer)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = close()
+	if err != nil {
+		return err
+	}
+
+	// 問題があった場合は、終了コードで判断できるようにエラーとする
+	if count > 0 {
+		return fmt.Errorf("%d problems found", count)
+	}
+
+	return nil
+}
+
+func check(scanner *csv.RecordScanner, noHeader bool, writer csv.CsvWriter) (int, error) {
+
+	err := writer.Write([]string{"Record", "Line", "Column", "Problem"})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	report := func(recordNum int, line int, column string, problem string) error {
+		count++
+		return writer.Write([]string{strconv.Itoa(recordNum), strconv.Itoa(line), column, problem})
+	}
+
+	var columnNames []string
+	columnName := func(columnNum int) string {
+		if columnNum == 0 {
+			return ""
+		}
+		if columnNum <= len(columnNames) {
+			return columnNames[columnNum-1]
+		}
+		return strconv.Itoa(columnNum)
+	}
+
+	for {
+		record, recordErr, err := scanner.Scan()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if recordErr != nil {
+			err := report(recordErr.Record, recordErr.Line, columnName(recordErr.Column), recordErr.Message)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if record == nil {
+			continue
+		}
+		recordNum, line := scanner.Position()
+		if columnNames == nil {
+			if noHeader {
+				columnNames = csv.ColumnNumbers(len(record))
+			} else {
+				columnNames = record
+			}
+		}
+
+		for i, field := range record {
+
+			// 変換できなかった文字は置換文字になっている
+			if strings.ContainsRune(field, '\uFFFD') {
+				if err := report(recordNum, line, columnName(i+1), "invalid character for the encoding"); err != nil {
+					return 0, err
+				}
+			}
+			// 先頭以外にあるBOM (ファイルを連結した場合など)
+			if strings.ContainsRune(field, '\uFEFF') {
+				if err := report(recordNum, line, columnName(i+1), "BOM in the field"); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckCmd(t *testing.T) {
+
+	s := "ID,Name,Note\r\n" +
+		"1,\"Yamada\r\nTaro\",x\r\n" +
+		"2,Sato\r\n" +
+		"3,Sa\"to,y\r\n" +
+		"4,\uFEFFSuzuki,\xff\r\n" +
+		"5,a,b,c\r\n" +
+		"6,\"x\"y,z\r\n" +
+		"7,ok,ok\r\n"
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"check",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "6 problems found" {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Record,Line,Column,Problem",
+		`3,4,,"wrong number of fields (expected 3, actual 2)"`,
+		"4,5,Name,bare quote in non quoted field",
+		"5,6,Name,BOM in the field",
+		"5,6,Note,invalid character for the encoding",
+		`6,7,,"wrong number of fields (expected 3, actual 4)"`,
+		"7,8,Name,unescaped quote in quoted field",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestCheckCmd_noProblem(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"check",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Record,Line,Column,Problem",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestCheckCmd_encoding(t *testing.T) {
+
+	// SJISとして不正なバイト
+	s := "ID,Name\r\n1,\x82\xa0\r\n2,\x82\r\n"
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"check",
+		"-i", fi,
+		"-o", fo,
+		"--encoding", "sjis",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "1 problems found" {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Record,Line,Column,Problem",
+		"3,3,Name,invalid character for the encoding",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", string(readBytes(t, fo)))
+	}
+}
+
+func TestCheckCmd_inputFileNotFound(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"check",
+		"-i", fi + "____", // 存在しないファイル
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	pathErr := err.(*os.PathError)
+	if pathErr.Path != fi+"____" || pathErr.Op != "open" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestOnError_skip(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2",
+		"3,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name",
+		"--on-error", "skip",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Name",
+		"Yamada",
+		"Sato",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_log(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2",
+		"3,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fl := createTempFile(t, "")
+	defer os.Remove(fl)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"count",
+		"-i", fi,
+		"--on-error", "log=" + fl,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fl)
+
+	if result != fi+": parse error on record 3 (line 3): wrong number of fields (expected 2, actual 1)\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_logOverwrite(t *testing.T) {
+
+	fi := createTempFile(t, joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2",
+		"3,Sato",
+	))
+	defer os.Remove(fi)
+
+	// 前回の実行結果は残らない
+	fl := createTempFile(t, "previous log\n")
+	defer os.Remove(fl)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"count",
+		"-i", fi,
+		"--on-error", "log=" + fl,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fl)
+
+	if result != fi+": parse error on record 3 (line 3): wrong number of fields (expected 2, actual 1)\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_logMultipleInputs(t *testing.T) {
+
+	f1 := createTempFile(t, joinRows(
+		"ID,Name",
+		"1",
+		"2,Sato",
+	))
+	defer os.Remove(f1)
+
+	f2 := createTempFile(t, joinRows(
+		"ID,Name",
+		"3,Suzuki",
+		"4",
+	))
+	defer os.Remove(f2)
+
+	fl := createTempFile(t, "")
+	defer os.Remove(fl)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", f1,
+		"-i", f2,
+		"-o", fo,
+		"--on-error", "log=" + fl,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fl)
+
+	expect := f1 + ": parse error on record 2 (line 2): wrong number of fields (expected 2, actual 1)\n" +
+		f2 + ": parse error on record 3 (line 3): wrong number of fields (expected 2, actual 1)\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_logRepeated(t *testing.T) {
+
+	fi := createTempFile(t, joinRows(
+		"ID,Name",
+		"1",
+		"2,Sato",
+	))
+	defer os.Remove(fi)
+
+	fl := createTempFile(t, "")
+	defer os.Remove(fl)
+
+	// 同じプロセスで繰り返し実行しても、前回の実行結果は残らない
+	for i := 0; i < 2; i++ {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"count",
+			"-i", fi,
+			"--on-error", "log=" + fl,
+		})
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+
+	result := readString(t, fl)
+
+	if result != fi+": parse error on record 2 (line 2): wrong number of fields (expected 2, actual 1)\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_logBatch(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte(joinRows("ID,Name", "1", "2,Sato")), 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), []byte(joinRows("ID,Name", "3,Suzuki", "4")), 0644)
+
+	outDir := createTempDir(t)
+	defer os.RemoveAll(outDir)
+
+	fl := createTempFile(t, "")
+	defer os.Remove(fl)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", dir,
+		"-o", outDir,
+		"--on-error", "log=" + fl,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 全てのファイルのエラーが1つのログに書き込まれる
+	result := readString(t, fl)
+
+	expect := filepath.Join(dir, "a.csv") + ": parse error on record 2 (line 2): wrong number of fields (expected 2, actual 1)\n" +
+		filepath.Join(dir, "b.csv") + ": parse error on record 3 (line 3): wrong number of fields (expected 2, actual 1)\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOnError_invalid(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("ID", "1"))
+	defer os.Remove(fi)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"count",
+		"-i", fi,
+		"--on-error", "log",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "flag on-error should be specified with fail, skip, pad or log=FILE" {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/onozaty/csvt/csv"
@@ -61,7 +60,12 @@ func runChoose(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func choose(reader csv.CsvReader, chooseColumnNames []string, writer csv.CsvWriter) error {
@@ -72,15 +76,9 @@ func choose(reader csv.CsvReader, chooseColumnNames []string, writer csv.CsvWrit
 		return errors.Wrap(err, "failed to read the CSV file")
 	}
 
-	chooseColumnIndexes := []int{}
-	for _, chooseColumnName := range chooseColumnNames {
-
-		chooseColumnIndex := slices.Index(columnNames, chooseColumnName)
-		if chooseColumnIndex == -1 {
-			return fmt.Errorf("missing %s in the CSV file", chooseColumnName)
-		}
-
-		chooseColumnIndexes = append(chooseColumnIndexes, chooseColumnIndex)
+	chooseColumnIndexes, err := getTargetColumnsIndexes(columnNames, chooseColumnNames)
+	if err != nil {
+		return err
 	}
 
 	// 指定されたカラムのみに絞るフィルタを定義
@@ -260,3 +260,295 @@ func TestChooseCmd_invalidFormat(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestChooseCmd_noHeader(t *testing.T) {
+
+	s := `1,Yamada,1
+5,Ichikawa,1
+2,"Hanako, Sato",3
+`
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "1",
+		"-c", "2",
+		"--no-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	// ヘッダはカラム番号に
+	expect := "1,2\r\n" +
+		"1,Yamada\r\n" +
+		"5,Ichikawa\r\n" +
+		"2,\"Hanako, Sato\"\r\n"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_noHeader_noOutputHeader(t *testing.T) {
+
+	s := `1,Yamada,1
+5,Ichikawa,1
+2,"Hanako, Sato",3
+`
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "3",
+		"--no-header",
+		"--no-output-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := "1\r\n" +
+		"1\r\n" +
+		"3\r\n"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_selector(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,price_a,price_b,Note",
+		"1,Yamada,10,20,x",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "1",
+		"-c", "price_*",
+		"-c", "last",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"ID,price_a,price_b,Note",
+		"1,10,20,x",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_selectorExclude(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,price_a,price_b,Note",
+		"1,Yamada,10,20,x",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "!/^price_/",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"ID,Name,Note",
+		"1,Yamada,x",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_duplicateHeader(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Name",
+		"1,Yamada,Taro",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name#2",
+		"-c", "ID",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"ID,Name",
+		"1,Taro",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_outputFormatJson(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Age",
+		"1,Yamada,20",
+		"2,Sato,",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "ID",
+		"-c", "Age",
+		"--output-format", "json",
+		"--infer-types",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := "[\n" +
+		`{"ID":1,"Age":20},` + "\n" +
+		`{"ID":2,"Age":null}` + "\n" +
+		"]\n"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_outputFormatNdjson(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name",
+		"--output-format", "ndjson",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := `{"Name":"Yamada"}` + "\n" +
+		`{"Name":"Sato"}` + "\n"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestChooseCmd_invalidOutputFormat(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"choose",
+		"-i", "input.csv",
+		"-o", "output.csv",
+		"-c", "ID",
+		"--output-format", "xml",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "flag output-format should be specified with csv, json, ndjson, xlsx, parquet, fixed, markdown, html or text" {
+		t.Fatal("failed test\n", err)
+	}
+}
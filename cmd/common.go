@@ -1,21 +1,93 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/onozaty/csvt/csv"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"golang.org/x/exp/slices"
 	"golang.org/x/text/encoding"
 )
 
 func getFlagBaseCsvFormat(f *pflag.FlagSet) (csv.Format, error) {
 
-	return getFlagCsvFormat(f, "delim", "quote", "sep", "allquote", "encoding", "bom")
+	format, err := getFlagCsvFormat(f, "delim", "quote", "sep", "allquote", "encoding", "bom")
+	if err != nil {
+		return format, err
+	}
+
+	format.OnError, format.OnErrorLogPath, err = getFlagOnError(f, "on-error")
+	if err != nil {
+		return format, err
+	}
+	if format.OnError == csv.OnErrorLog {
+		format.OnErrorLogWriter = getFlagOnErrorLogWriter(f, "on-error")
+	}
+
+	format.NoHeader, _ = f.GetBool("no-header")
+	format.NoOutputHeader, _ = f.GetBool("no-output-header")
+	format.DedupeHeaders, _ = f.GetBool("dedupe-headers")
+
+	format.OutputFormat, err = getFlagOutputFormat(f, "output-format")
+	if err != nil {
+		return format, err
+	}
+	format.InferTypes, _ = f.GetBool("infer-types")
+
+	format.InputFormat, err = getFlagInputFormat(f, "input-format")
+	if err != nil {
+		return format, err
+	}
+	format.JsonArray, err = getFlagJsonArray(f, "json-array")
+	if err != nil {
+		return format, err
+	}
+	format.Sheet, _ = f.GetString("sheet")
+
+	if schemaPath, _ := f.GetString("parquet-schema"); schemaPath != "" {
+		format.Schema, err = csv.LoadSchema(schemaPath)
+		if err != nil {
+			return format, err
+		}
+	}
+
+	if specPath, _ := f.GetString("fixed-spec"); specPath != "" {
+		format.FixedWidthSpec, err = csv.LoadFixedWidthSpec(specPath)
+		if err != nil {
+			return format, err
+		}
+	}
+	format.TableMaxWidth, _ = f.GetInt("table-max-width")
+	format.TablePageSize, _ = f.GetInt("table-page-size")
+	if format.TableMaxWidth < 0 {
+		return format, fmt.Errorf("table-max-width must be greater than or equal to 0")
+	}
+	if format.TablePageSize < 0 {
+		return format, fmt.Errorf("table-page-size must be greater than or equal to 0")
+	}
+
+	format.Compression, _ = f.GetString("compress")
+	switch format.Compression {
+	case "", csv.CompressionNone, csv.CompressionGzip, csv.CompressionZstd, csv.CompressionBzip2, csv.CompressionXz:
+	default:
+		return format, fmt.Errorf("flag compress should be specified with gzip, zstd, bzip2, xz or none")
+	}
+	format.CompressionLevel, _ = f.GetInt("compress-level")
+	if format.CompressionLevel < 0 {
+		return format, fmt.Errorf("compress-level must be greater than or equal to 0")
+	}
+
+	if (format.InputFormat == csv.InputFormatFixed || format.OutputFormat == csv.OutputFormatFixed) && format.FixedWidthSpec == nil {
+		return format, fmt.Errorf("flag fixed-spec is required for the fixed format")
+	}
+
+	return format, nil
 }
 
 func getFlagCsvFormat(f *pflag.FlagSet, delimName string, quoteName string, sepName string, allquoteName string, encodingName string, bomName string) (csv.Format, error) {
@@ -102,6 +174,145 @@ func getFlagEncoding(f *pflag.FlagSet, name string) (encoding.Encoding, error) {
 	return csv.Encoding(str)
 }
 
+func getFlagOnError(f *pflag.FlagSet, name string) (string, string, error) {
+
+	str, _ := f.GetString(name)
+
+	switch {
+	case str == "":
+		return "", "", nil
+	case str == csv.OnErrorFail || str == csv.OnErrorSkip || str == csv.OnErrorPad:
+		return str, "", nil
+	case strings.HasPrefix(str, csv.OnErrorLog+"="):
+		// log=FILE
+		logPath := strings.TrimPrefix(str, csv.OnErrorLog+"=")
+		if logPath != "" {
+			return csv.OnErrorLog, logPath, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("flag %s should be specified with fail, skip, pad or log=FILE", name)
+}
+
+// --on-error のフラグの値
+// log=FILE の場合は、コマンドの実行時に一度だけ開いたログの書き込み先も保持する
+type onErrorFlagValue struct {
+	value string
+	log   io.Writer
+}
+
+func (v *onErrorFlagValue) String() string {
+	return v.value
+}
+
+func (v *onErrorFlagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *onErrorFlagValue) Type() string {
+	return "string"
+}
+
+func getFlagOnErrorLogWriter(f *pflag.FlagSet, name string) io.Writer {
+
+	flag := f.Lookup(name)
+	if flag == nil {
+		return nil
+	}
+	if value, ok := flag.Value.(*onErrorFlagValue); ok {
+		return value.log
+	}
+	return nil
+}
+
+// --on-error log=FILE のログを、コマンドの実行ごとに一度だけ開いて各処理で共有するように
+// 前回の実行結果が残らないように切り詰め、複数の入力ファイル(バッチ処理も含む)は同じログに書き込む
+func enableOnErrorLog(c *cobra.Command) {
+
+	if c.RunE == nil {
+		return
+	}
+
+	runE := c.RunE
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+
+		flag := cmd.Flags().Lookup("on-error")
+		if flag == nil {
+			return runE(cmd, args)
+		}
+		value, ok := flag.Value.(*onErrorFlagValue)
+		if !ok {
+			return runE(cmd, args)
+		}
+
+		onError, logPath, err := getFlagOnError(cmd.Flags(), "on-error")
+		if err != nil || onError != csv.OnErrorLog {
+			// 指定の誤りは各コマンドでエラーにする
+			return runE(cmd, args)
+		}
+
+		log, err := os.Create(logPath)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		defer log.Close()
+
+		value.log = log
+		defer func() { value.log = nil }()
+
+		if err := runE(cmd, args); err != nil {
+			return err
+		}
+
+		return log.Close()
+	}
+}
+
+func getFlagOutputFormat(f *pflag.FlagSet, name string) (string, error) {
+
+	outputFormat, _ := f.GetString(name)
+
+	switch outputFormat {
+	case "", csv.OutputFormatCsv:
+		return csv.OutputFormatCsv, nil
+	case csv.OutputFormatJson, csv.OutputFormatNdjson, csv.OutputFormatXlsx, csv.OutputFormatParquet, csv.OutputFormatFixed,
+		csv.OutputFormatMarkdown, csv.OutputFormatHtml, csv.OutputFormatText:
+		return outputFormat, nil
+	}
+
+	return "", fmt.Errorf("flag %s should be specified with csv, json, ndjson, xlsx, parquet, fixed, markdown, html or text", name)
+}
+
+func getFlagInputFormat(f *pflag.FlagSet, name string) (string, error) {
+
+	inputFormat, _ := f.GetString(name)
+
+	switch inputFormat {
+	case "", csv.InputFormatCsv:
+		return csv.InputFormatCsv, nil
+	case csv.InputFormatJson, csv.InputFormatNdjson, csv.InputFormatXlsx, csv.InputFormatParquet, csv.InputFormatFixed:
+		return inputFormat, nil
+	}
+
+	return "", fmt.Errorf("flag %s should be specified with csv, json, ndjson, xlsx, parquet or fixed", name)
+}
+
+func getFlagJsonArray(f *pflag.FlagSet, name string) (string, error) {
+
+	jsonArray, _ := f.GetString(name)
+
+	switch jsonArray {
+	case "", csv.JsonArrayJson:
+		return csv.JsonArrayJson, nil
+	case csv.JsonArrayIndex, csv.JsonArrayJoin:
+		return jsonArray, nil
+	}
+
+	return "", fmt.Errorf("flag %s should be specified with json, index or join", name)
+}
+
 func getTargetColumnsIndexes(allColumnNames []string, targetColumnNames []string) ([]int, error) {
 
 	if len(targetColumnNames) == 0 {
@@ -117,15 +328,9 @@ func getTargetColumnsIndexes(allColumnNames []string, targetColumnNames []string
 
 	} else {
 
-		targetColumnIndexes := []int{}
-		for _, targetColumnName := range targetColumnNames {
-
-			targetColumnIndex, err := getTargetColumnIndex(allColumnNames, targetColumnName)
-			if err != nil {
-				return nil, err
-			}
-
-			targetColumnIndexes = append(targetColumnIndexes, targetColumnIndex)
+		targetColumnIndexes, err := csv.ColumnIndexes(allColumnNames, targetColumnNames)
+		if err != nil {
+			return nil, columnNotFoundMessage(err, "CSV file")
 		}
 
 		return targetColumnIndexes, nil
@@ -134,47 +339,197 @@ func getTargetColumnsIndexes(allColumnNames []string, targetColumnNames []string
 
 func getTargetColumnIndex(allColumnNames []string, targetColumnName string) (int, error) {
 
-	targetColumnIndex := slices.Index(allColumnNames, targetColumnName)
-	if targetColumnIndex == -1 {
-		return -1, fmt.Errorf("missing %s in the CSV file", targetColumnName)
+	return findColumnIndex(allColumnNames, targetColumnName, "CSV file")
+}
+
+func findColumnIndex(allColumnNames []string, targetColumnName string, fileName string) (int, error) {
+
+	targetColumnIndex, err := csv.ColumnIndex(allColumnNames, targetColumnName)
+	if err != nil {
+		return -1, columnNotFoundMessage(err, fileName)
 	}
 
 	return targetColumnIndex, nil
 }
 
+func columnNotFoundMessage(err error, fileName string) error {
+
+	var notFoundErr *csv.ColumnNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return fmt.Errorf("missing %s in the %s", notFoundErr.Selector, fileName)
+	}
+
+	return err
+}
+
 func setupInput(inputPath string, format csv.Format) (csv.CsvReader, func(), error) {
 
+	input, close, err := openInputFile(inputPath, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if format.OnErrorLogWriter != nil {
+		// 複数の入力ファイルで同じログに書き込むので、どのファイルかを付与
+		format.OnErrorLogWriter = &onErrorLogWriter{w: format.OnErrorLogWriter, inputPath: inputPath}
+	}
+
+	reader := csv.NewCsvReader(input, format)
+
+	return reader, close, nil
+}
+
+// --on-error log=FILE のログの各行に、入力ファイルのパスを付与するWriter
+// (1件のエラーは1回のWriteで書き込まれる)
+type onErrorLogWriter struct {
+	w         io.Writer
+	inputPath string
+}
+
+func (w *onErrorLogWriter) Write(p []byte) (int, error) {
+
+	if _, err := io.WriteString(w.w, w.inputPath+": "); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+func setupOutput(outputPath string, format csv.Format) (csv.CsvWriter, func() error, error) {
+
+	output, close, err := createOutputFile(outputPath, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer := csv.NewCsvWriter(output, format)
+
+	return writer, close, nil
+}
+
+// 入力ファイルを開く (圧縮されている場合は先頭のバイト列から判断して展開する)
+// archive.zip!/dir/file.csv のように、アーカイブ内のファイルも指定できる
+func openInputFile(inputPath string, format csv.Format) (io.Reader, func(), error) {
+
+	if archivePath, member, ok := csv.SplitArchivePath(inputPath); ok {
+		return openArchiveMember(archivePath, member, format)
+	}
+
 	inputFile, err := os.Open(inputPath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	reader := csv.NewCsvReader(inputFile, format)
+	head := make([]byte, csv.CompressionMagicLength)
+	n, _ := inputFile.ReadAt(head, 0)
+	compression := csv.DetectCompression(head[:n])
+
+	if compression == csv.CompressionNone {
+		// そのまま渡すことで、ランダムアクセスが必要な形式(parquet)でも全体を読み込まずに済む
+		return inputFile, func() { inputFile.Close() }, nil
+	}
+
+	reader, err := csv.NewDecompressReader(inputFile, compression)
+	if err != nil {
+		inputFile.Close()
+		return nil, nil, errors.Wrapf(err, "failed to decompress %s", inputPath)
+	}
 
 	close := func() {
+		reader.Close()
 		inputFile.Close()
 	}
 
 	return reader, close, nil
 }
 
-func setupOutput(outputPath string, format csv.Format) (csv.CsvWriter, func(), error) {
+func openArchiveMember(archivePath string, member string, format csv.Format) (io.Reader, func(), error) {
+
+	if csv.HasGlobPattern(member) {
+		// 1つに特定できる場合のみ
+		matched, err := csv.MatchArchiveMembers(archivePath, member, format.Encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matched) == 0 {
+			return nil, nil, fmt.Errorf("no file matches %s", archivePath+csv.ArchiveSeparator+member)
+		}
+		if len(matched) > 1 {
+			return nil, nil, fmt.Errorf("%s matches multiple files", archivePath+csv.ArchiveSeparator+member)
+		}
+		member = matched[0]
+	}
 
-	outputFile, err := os.Create(outputPath)
+	memberReader, memberClose, err := csv.OpenArchiveMember(archivePath, member, format.Encoding)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	writer := csv.NewCsvWriter(outputFile, format)
+	br := bufio.NewReader(memberReader)
+	head, _ := br.Peek(csv.CompressionMagicLength)
+	reader, err := csv.NewDecompressReader(br, csv.DetectCompression(head))
+	if err != nil {
+		memberClose()
+		return nil, nil, errors.Wrapf(err, "failed to decompress %s", member)
+	}
 
 	close := func() {
+		reader.Close()
+		memberClose()
+	}
+
+	return reader, close, nil
+}
+
+// 出力ファイルを作成する (--compress もしくは拡張子で指定された形式で圧縮する)
+// 圧縮の終端はcloseで書き込まれるので、closeの前にWriterのFlushを行うこと
+func createOutputFile(outputPath string, format csv.Format) (io.Writer, func() error, error) {
+
+	return openOutputFile(outputPath, format, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+// 返却するcloseは、圧縮の終端の書き込みなどのエラーを返す (複数回呼ばれても2回目以降は何もしない)
+func openOutputFile(outputPath string, format csv.Format, flag int) (io.Writer, func() error, error) {
+
+	compression := getOutputCompression(outputPath, format)
+
+	outputFile, err := os.OpenFile(outputPath, flag, 0666)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer, err := csv.NewCompressWriter(outputFile, compression, format.CompressionLevel)
+	if err != nil {
 		outputFile.Close()
+		return nil, nil, err
+	}
+
+	closed := false
+	close := func() error {
+		if closed {
+			return nil
+		}
+		closed = true
+
+		err := writer.Close()
+		if fileErr := outputFile.Close(); err == nil {
+			err = fileErr
+		}
+		return err
 	}
 
 	return writer, close, nil
 }
 
-func setupInputOutput(inputPath string, outputPath string, format csv.Format) (csv.CsvReader, csv.CsvWriter, func(), error) {
+// 出力の圧縮形式 (--compressの指定が無い場合は拡張子から判断)
+func getOutputCompression(outputPath string, format csv.Format) string {
+
+	if format.Compression != "" {
+		return format.Compression
+	}
+	return csv.CompressionByExtension(outputPath)
+}
+
+func setupInputOutput(inputPath string, outputPath string, format csv.Format) (csv.CsvReader, csv.CsvWriter, func() error, error) {
 
 	reader, inputClose, err := setupInput(inputPath, format)
 	if err != nil {
@@ -187,9 +542,15 @@ func setupInputOutput(inputPath string, outputPath string, format csv.Format) (c
 		return nil, nil, nil, err
 	}
 
-	allClose := func() {
+	closed := false
+	allClose := func() error {
+		if closed {
+			return nil
+		}
+		closed = true
+
 		inputClose()
-		outputClose()
+		return outputClose()
 	}
 
 	return reader, writer, allClose, nil
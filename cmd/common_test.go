@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/onozaty/csvt/csv"
 )
 
 func createTempFile(t *testing.T, content string) string {
@@ -72,3 +76,38 @@ func readDir(t *testing.T, dir string) map[string][]byte {
 
 	return contentsMap
 }
+
+func createCompressedFile(t *testing.T, content string, compression string) string {
+
+	b := &bytes.Buffer{}
+	w, err := csv.NewCompressWriter(b, compression, 0)
+	if err != nil {
+		t.Fatal("compress failed\n", err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal("compress failed\n", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("compress failed\n", err)
+	}
+
+	return createTempFile(t, b.String())
+}
+
+func readDecompressedString(t *testing.T, name string) string {
+
+	b := readBytes(t, name)
+
+	r, err := csv.NewDecompressReader(bytes.NewReader(b), csv.DetectCompression(b))
+	if err != nil {
+		t.Fatal("decompress failed\n", err)
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal("decompress failed\n", err)
+	}
+
+	return string(decompressed)
+}
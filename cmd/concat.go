@@ -23,34 +23,65 @@ func newConcatCmd() *cobra.Command {
 
 			inputPaths, _ := cmd.Flags().GetStringArray("input")
 			outputPath, _ := cmd.Flags().GetString("output")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			union, _ := cmd.Flags().GetBool("union")
+			intersect, _ := cmd.Flags().GetBool("intersect")
+			fill, _ := cmd.Flags().GetString("fill")
+			sourceColumnName, _ := cmd.Flags().GetString("source-column")
+
+			if union && intersect {
+				return fmt.Errorf("not allowed to specify both --union and --intersect")
+			}
+			if cmd.Flags().Changed("fill") && !union {
+				return fmt.Errorf("fill can only be used with --union")
+			}
 
 			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
 			cmd.SilenceUsage = true
 
-			return runConcat(format, inputPaths, outputPath)
+			return runConcat(
+				format,
+				inputPaths,
+				recursive,
+				outputPath,
+				ConcatOptions{
+					Union:            union,
+					Intersect:        intersect,
+					Fill:             fill,
+					SourceColumnName: sourceColumnName,
+				})
 		},
 	}
 
-	concatCmd.Flags().StringArrayP("input", "i", []string{}, "Input CSV files path.")
+	concatCmd.Flags().StringArrayP("input", "i", []string{}, "Input CSV files path. A pattern (logs/*.csv) or a directory can also be specified.")
 	concatCmd.MarkFlagRequired("input")
 	concatCmd.Flags().StringP("output", "o", "", "Output CSV file path.")
 	concatCmd.MarkFlagRequired("output")
+	concatCmd.Flags().BoolP("union", "", false, "(optional) Output the union of the columns of all files. Missing columns are filled with the value of --fill.")
+	concatCmd.Flags().BoolP("intersect", "", false, "(optional) Output only the columns that exist in all files.")
+	concatCmd.Flags().StringP("fill", "", "", "(optional) Value for the missing columns with --union. The default is empty.")
+	concatCmd.Flags().StringP("source-column", "", "", "(optional) Name of the column to add with the input file path of each row.")
 
 	return concatCmd
 }
 
-func runConcat(format csv.Format, inputPaths []string, outputPath string) error {
+type ConcatOptions struct {
+	Union            bool
+	Intersect        bool
+	Fill             string
+	SourceColumnName string
+}
 
-	readers := []csv.CsvReader{}
+func runConcat(format csv.Format, inputPaths []string, recursive bool, outputPath string, options ConcatOptions) error {
 
-	for _, inputPath := range inputPaths {
-		reader, inputClose, err := setupInput(inputPath, format)
-		if err != nil {
-			return err
-		}
-		defer inputClose()
+	inputPaths, err := expandInputPaths(inputPaths, format, recursive)
+	if err != nil {
+		return err
+	}
 
-		readers = append(readers, reader)
+	headers, err := readConcatHeaders(inputPaths, format)
+	if err != nil {
+		return err
 	}
 
 	writer, outputClose, err := setupOutput(outputPath, format)
@@ -59,90 +90,197 @@ func runConcat(format csv.Format, inputPaths []string, outputPath string) error
 	}
 	defer outputClose()
 
-	err = concat(readers, writer)
+	err = concat(inputPaths, headers, format, writer, options)
 	if err != nil {
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return outputClose()
+}
+
+// 同じ名前のカラムが複数ある場合に区別するため、出現順と組にしたもの
+type concatColumn struct {
+	name       string
+	occurrence int
+}
+
+// ヘッダだけを読む (同時に開くファイルが増えないように、ヘッダを読んだら閉じる)
+func readConcatHeaders(inputPaths []string, format csv.Format) ([][]string, error) {
+
+	// 不正なレコードは本体を読む際にログに書き込むので、ここでは書き込まない
+	format.OnErrorLogWriter = nil
+
+	headers := [][]string{}
+	for i, inputPath := range inputPaths {
+		reader, inputClose, err := setupInput(inputPath, format)
+		if err != nil {
+			return nil, err
+		}
+		columnNames, err := reader.Read()
+		inputClose()
+		if err != nil {
+			return nil, concatReadError(err, i)
+		}
+		headers = append(headers, columnNames)
+	}
+
+	return headers, nil
 }
 
-func concat(readers []csv.CsvReader, writer csv.CsvWriter) error {
+func concat(inputPaths []string, headers [][]string, format csv.Format, writer csv.CsvWriter, options ConcatOptions) error {
 
-	firstReader := readers[0]
-	firstColumnNames, err := firstReader.Read()
+	// 全てのヘッダから、出力するカラムを決める
+	outputColumns := concatColumns(headers[0])
+	if options.Union {
+		outputColumns = unionConcatColumns(headers)
+	} else if options.Intersect {
+		outputColumns = intersectConcatColumns(headers)
+	}
+
+	outputColumnNames := []string{}
+	for _, column := range outputColumns {
+		outputColumnNames = append(outputColumnNames, column.name)
+	}
+	if options.SourceColumnName != "" {
+		outputColumnNames = append(outputColumnNames, options.SourceColumnName)
+	}
+
+	err := writer.Write(outputColumnNames)
 	if err != nil {
-		return errors.Wrap(err, "failed to read the first CSV file")
+		return err
 	}
 
-	// 1つ目の書き込み
-	err = writer.Write(firstColumnNames)
+	for i, inputPath := range inputPaths {
+		count := i + 1
+
+		if !options.Union && !options.Intersect && len(headers[0]) != len(headers[i]) {
+			return fmt.Errorf("number of columns does not match (%d)", count)
+		}
+
+		// 出力するカラムとのマッピングを作成 (存在しないカラムは-1)
+		columnIndexes := []int{}
+		for _, column := range outputColumns {
+
+			// カラム名の完全一致で対応付け
+			// (同じ名前のカラムが複数ある場合は、出現順で対応付け)
+			columnIndex := csv.NthColumnIndex(headers[i], column.name, column.occurrence)
+			if columnIndex == -1 && !options.Union {
+				return errors.Wrapf(fmt.Errorf("missing %s in the CSV file", column.name), "no column corresponding in CSV file (%d)", count)
+			}
+
+			columnIndexes = append(columnIndexes, columnIndex)
+		}
+
+		// ファイルは1つずつ開き直して出力する
+		err := concatFile(inputPath, format, i, columnIndexes, writer, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func concatFile(inputPath string, format csv.Format, index int, columnIndexes []int, writer csv.CsvWriter, options ConcatOptions) error {
+
+	reader, inputClose, err := setupInput(inputPath, format)
 	if err != nil {
 		return err
 	}
+	defer inputClose()
+
+	// ヘッダは読み込み済み
+	_, err = reader.Read()
+	if err != nil {
+		return concatReadError(err, index)
+	}
 
 	for {
-		row, err := firstReader.Read()
+		row, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return errors.Wrap(err, "failed to read the first CSV file")
+			return concatReadError(err, index)
 		}
 
-		err = writer.Write(row)
+		// 出力するカラムに合わせてカラム入れ替え
+		swapedRow := []string{}
+		for _, columnIndex := range columnIndexes {
+			if columnIndex == -1 {
+				swapedRow = append(swapedRow, options.Fill)
+			} else {
+				swapedRow = append(swapedRow, row[columnIndex])
+			}
+		}
+		if options.SourceColumnName != "" {
+			swapedRow = append(swapedRow, inputPath)
+		}
+
+		err = writer.Write(swapedRow)
 		if err != nil {
 			return err
 		}
 	}
 
-	// 2つ目以降
-	count := 1
-	for _, reader := range readers[1:] {
-		count++
+	return nil
+}
 
-		columnNames, err := reader.Read()
-		if err != nil {
-			return errors.Wrapf(err, "failed to read CSV file (%d)", count)
-		}
+func concatReadError(err error, index int) error {
 
-		if len(firstColumnNames) != len(columnNames) {
-			return fmt.Errorf("number of columns does not match (%d)", count)
-		}
+	if index == 0 {
+		return errors.Wrap(err, "failed to read the first CSV file")
+	}
+	return errors.Wrapf(err, "failed to read CSV file (%d)", index+1)
+}
 
-		// 1つ目のCSVのカラム名とのカラム名のマッピングを作成
-		columnIndexes := []int{}
-		for _, firstColumnName := range firstColumnNames {
+func concatColumns(columnNames []string) []concatColumn {
 
-			columnIndex, err := getTargetColumnIndex(columnNames, firstColumnName)
-			if err != nil {
-				return errors.Wrapf(err, "no column corresponding in CSV file (%d)", count)
-			}
+	columns := []concatColumn{}
+	occurrences := map[string]int{}
+	for _, columnName := range columnNames {
+		occurrences[columnName]++
+		columns = append(columns, concatColumn{name: columnName, occurrence: occurrences[columnName]})
+	}
+	return columns
+}
 
-			columnIndexes = append(columnIndexes, columnIndex)
-		}
+// 全てのファイルのカラムを出現順に
+func unionConcatColumns(headers [][]string) []concatColumn {
 
-		for {
-			row, err := reader.Read()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return errors.Wrapf(err, "failed to read CSV file (%d)", count)
+	columns := []concatColumn{}
+	exists := map[concatColumn]bool{}
+	for _, header := range headers {
+		for _, column := range concatColumns(header) {
+			if !exists[column] {
+				exists[column] = true
+				columns = append(columns, column)
 			}
+		}
+	}
+	return columns
+}
 
-			// 1つ目のCSVに合わせてカラム入れ替え
-			swapedRow := []string{}
-			for _, columnIndex := range columnIndexes {
-				swapedRow = append(swapedRow, row[columnIndex])
-			}
+// 全てのファイルに存在するカラムを、1つ目のファイルの順に
+func intersectConcatColumns(headers [][]string) []concatColumn {
 
-			err = writer.Write(swapedRow)
-			if err != nil {
-				return err
-			}
+	counts := map[concatColumn]int{}
+	for _, header := range headers {
+		for _, column := range concatColumns(header) {
+			counts[column]++
 		}
 	}
 
-	return nil
+	columns := []concatColumn{}
+	for _, column := range concatColumns(headers[0]) {
+		if counts[column] == len(headers) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
 }
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/onozaty/csvt/csv"
+	"golang.org/x/text/encoding/japanese"
 )
 
 func TestConcatCmd(t *testing.T) {
@@ -333,7 +339,7 @@ func TestConcatCmd_firstFileNotFound(t *testing.T) {
 
 func TestConcatCmd_secondFileNotFound(t *testing.T) {
 
-	fi1 := createTempFile(t, "")
+	fi1 := createTempFile(t, "col1\r\n")
 	defer os.Remove(fi1)
 
 	fi2 := createTempFile(t, "")
@@ -363,10 +369,10 @@ func TestConcatCmd_secondFileNotFound(t *testing.T) {
 
 func TestConcatCmd_outputFileNotFound(t *testing.T) {
 
-	fi1 := createTempFile(t, "")
+	fi1 := createTempFile(t, "col1\r\n")
 	defer os.Remove(fi1)
 
-	fi2 := createTempFile(t, "")
+	fi2 := createTempFile(t, "col1\r\n")
 	defer os.Remove(fi2)
 
 	fo := createTempFile(t, "")
@@ -390,3 +396,457 @@ func TestConcatCmd_outputFileNotFound(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestConcatCmd_duplicateHeader(t *testing.T) {
+
+	s1 := `col1,col2,col1
+1,x2,x3
+`
+	fi1 := createTempFile(t, s1)
+	defer os.Remove(fi1)
+
+	s2 := `col2,col1,col1
+a2,2,a3
+`
+	fi2 := createTempFile(t, s2)
+	defer os.Remove(fi2)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fi1,
+		"-i", fi2,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	// 同じ名前のカラムは出現順で対応付け
+	expect := joinRows(
+		"col1,col2,col1",
+		"1,x2,x3",
+		"2,a2,a3",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_archive(t *testing.T) {
+
+	name, err := japanese.ShiftJIS.NewEncoder().String("売上/東京.csv")
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	gz := &bytes.Buffer{}
+	gw, err := csv.NewCompressWriter(gz, csv.CompressionGzip, 0)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	gw.Write([]byte(joinRows("ID,Name", "3,gz")))
+	gw.Close()
+
+	b := &bytes.Buffer{}
+	zw := zip.NewWriter(b)
+	tokyo, err := japanese.ShiftJIS.NewEncoder().String(joinRows("ID,Name", "1,東京"))
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	members := []struct {
+		name    string
+		content string
+		nonUTF8 bool
+	}{
+		{"売上/b.csv", joinRows("ID,Name", "2,b"), false},
+		{name, tokyo, true},
+		{"売上/c.csv.gz", gz.String(), false},
+		{"other/d.csv", joinRows("ID,Name", "4,d"), false},
+	}
+	for _, m := range members {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, NonUTF8: m.nonUTF8})
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		w.Write([]byte(m.content))
+	}
+	zw.Close()
+
+	fa := createTempFile(t, b.String())
+	defer os.Remove(fa)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fa + "!/売上/*",
+		"-i", fa + "!/other/d.csv",
+		"-o", fo,
+		"--encoding", "shift_jis",
+	})
+
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result, err := japanese.ShiftJIS.NewDecoder().String(readString(t, fo))
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// アーカイブ内のパターンは名前順に展開
+	expect := joinRows(
+		"ID,Name",
+		"2,b",
+		"3,gz",
+		"1,東京",
+		"4,d",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_archiveNotMatch(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	zw := zip.NewWriter(b)
+	w, _ := zw.Create("a.csv")
+	w.Write([]byte(joinRows("ID", "1")))
+	zw.Close()
+
+	fa := createTempFile(t, b.String())
+	defer os.Remove(fa)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fa + "!/a.csv",
+		"-i", fa + "!/*.tsv",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "no file matches "+fa+"!/*.tsv" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestConcatCmd_pattern(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "2024-02.csv"), []byte("col1,col2\r\n3,4\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "2024-01.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "2023-12.csv"), []byte("col1,col2\r\nx,y\r\n"), 0644)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", filepath.Join(dir, "2024-*.csv"),
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2",
+		"1,2",
+		"3,4",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_directoryRecursive(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.csv"), []byte("col1,col2\r\n3,4\r\n"), 0644)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", dir,
+		"--recursive",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2",
+		"1,2",
+		"3,4",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_directoryNotRecursive(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("col1,col2\r\n1,2\r\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.csv"), []byte("col1,col2\r\n3,4\r\n"), 0644)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", dir,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2",
+		"1,2",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_patternNotMatch(t *testing.T) {
+
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	pattern := filepath.Join(dir, "*.csv")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", pattern,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "no file matches "+pattern {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestConcatCmd_union(t *testing.T) {
+
+	s1 := `col1,col2
+1,2
+`
+	fi1 := createTempFile(t, s1)
+	defer os.Remove(fi1)
+
+	s2 := `col2,col3,col1
+a,b,c
+`
+	fi2 := createTempFile(t, s2)
+	defer os.Remove(fi2)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fi1,
+		"-i", fi2,
+		"-o", fo,
+		"--union",
+		"--fill", "-",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2,col3",
+		"1,2,-",
+		"c,a,b",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_intersect(t *testing.T) {
+
+	s1 := `col1,col2,col3
+1,2,3
+`
+	fi1 := createTempFile(t, s1)
+	defer os.Remove(fi1)
+
+	s2 := `col3,col4,col1
+a,b,c
+`
+	fi2 := createTempFile(t, s2)
+	defer os.Remove(fi2)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fi1,
+		"-i", fi2,
+		"-o", fo,
+		"--intersect",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col3",
+		"1,3",
+		"c,a",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_sourceColumn(t *testing.T) {
+
+	s1 := `col1,col2
+1,2
+2,3
+`
+	fi1 := createTempFile(t, s1)
+	defer os.Remove(fi1)
+
+	s2 := `col1,col2
+x,y
+`
+	fi2 := createTempFile(t, s2)
+	defer os.Remove(fi2)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", fi1,
+		"-i", fi2,
+		"-o", fo,
+		"--source-column", "source",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2,source",
+		"1,2,"+fi1,
+		"2,3,"+fi1,
+		"x,y,"+fi2,
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestConcatCmd_unionAndIntersect(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", "a.csv",
+		"-i", "b.csv",
+		"-o", "c.csv",
+		"--union",
+		"--intersect",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "not allowed to specify both --union and --intersect" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestConcatCmd_fillWithoutUnion(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"concat",
+		"-i", "a.csv",
+		"-i", "b.csv",
+		"-o", "c.csv",
+		"--fill", "x",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "fill can only be used with --union" {
+		t.Fatal("failed test\n", err)
+	}
+}
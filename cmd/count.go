@@ -1,13 +1,11 @@
 package cmd
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/onozaty/csvt/csv"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"golang.org/x/exp/slices"
 )
 
 func newCountCmd() *cobra.Command {
@@ -81,9 +79,9 @@ func count(reader csv.CsvReader, options CountOptions) (int, error) {
 
 	targetColumnIndex := -1
 	if options.targetColumnName != "" {
-		targetColumnIndex = slices.Index(columnNames, options.targetColumnName)
-		if targetColumnIndex == -1 {
-			return 0, fmt.Errorf("missing %s in the CSV file", options.targetColumnName)
+		targetColumnIndex, err = getTargetColumnIndex(columnNames, options.targetColumnName)
+		if err != nil {
+			return 0, err
 		}
 	}
 
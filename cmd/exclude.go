@@ -1,13 +1,11 @@
 package cmd
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/onozaty/csvt/csv"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"golang.org/x/exp/slices"
 )
 
 func newExcludeCmd() *cobra.Command {
@@ -79,7 +77,12 @@ func runExclude(format csv.Format, inputPath string, targetColumnName string, an
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func exclude(reader csv.CsvReader, targetColumnName string, anotherReader csv.CsvReader, writer csv.CsvWriter, options ExcludeOptions) error {
@@ -94,9 +97,9 @@ func exclude(reader csv.CsvReader, targetColumnName string, anotherReader csv.Cs
 	if err != nil {
 		return errors.Wrap(err, "failed to read the input CSV file")
 	}
-	inputTargetColumnIndex := slices.Index(inputColumnNames, inputTargetColumnName)
-	if inputTargetColumnIndex == -1 {
-		return fmt.Errorf("missing %s in the input CSV file", inputTargetColumnName)
+	inputTargetColumnIndex, err := findColumnIndex(inputColumnNames, inputTargetColumnName, "input CSV file")
+	if err != nil {
+		return err
 	}
 
 	anotherItemSet, err := csv.LoadItemSet(anotherReader, anotherTargetColumnName)
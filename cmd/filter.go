@@ -103,7 +103,12 @@ func runFilter(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func filter(reader csv.CsvReader, targetColumnNames []string, writer csv.CsvWriter, options FilterOptions) error {
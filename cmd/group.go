@@ -63,7 +63,12 @@ func runGroupCount(format csv.Format, inputPath string, targetColumnName string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func groupCount(reader csv.CsvReader, targetColumnName string, countColumnName string, writer csv.CsvWriter) error {
@@ -94,7 +99,7 @@ func groupCount(reader csv.CsvReader, targetColumnName string, countColumnName s
 		counter[val] = counter[val] + 1
 	}
 
-	if err := writer.Write([]string{targetColumnName, countColumnName}); err != nil {
+	if err := writer.Write([]string{columnNames[targetColumnIndex], countColumnName}); err != nil {
 		return err
 	}
 
@@ -246,3 +246,173 @@ func TestGroupCmd_inputFileEmpty(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestGroupCmd_selector(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,A",
+		"2,B",
+		"3,A",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", fi,
+		"-o", fo,
+		"-c", "last",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	// ヘッダは実際のカラム名
+	expect := joinRows(
+		"col2,COUNT",
+		"A,2",
+		"B,1",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestGroupCmd_selectorMultiple(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,A",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col*",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "col* matches multiple columns" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestGroupCmd_duplicateHeader(t *testing.T) {
+
+	s := joinRows(
+		"col1,col1",
+		"1,A",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col1",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "col1 is duplicated in the header (specify like col1#2, or use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestGroupCmd_inputFormatNdjson(t *testing.T) {
+
+	s := `{"id": 1, "user": {"country": "JP"}}
+{"id": 2, "user": {"country": "US"}}
+{"id": 3, "user": {"country": "JP"}}
+`
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", fi,
+		"-o", fo,
+		"-c", "user.country",
+		"--input-format", "ndjson",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"user.country,COUNT",
+		"JP,2",
+		"US,1",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestGroupCmd_invalidInputFormat(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", "input.csv",
+		"-o", "output.csv",
+		"-c", "col1",
+		"--input-format", "xml",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "flag input-format should be specified with csv, json, ndjson, xlsx, parquet or fixed" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestGroupCmd_invalidJsonArray(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"group",
+		"-i", "input.csv",
+		"-o", "output.csv",
+		"-c", "col1",
+		"--json-array", "x",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "flag json-array should be specified with json, index or join" {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -66,11 +66,7 @@ func head(reader csv.CsvReader, number int, writer io.Writer) error {
 		return errors.Wrap(err, "failed to read the input CSV file")
 	}
 
-	table := tablewriter.NewWriter(writer)
-	table.SetAutoFormatHeaders(false)
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetHeader(columnNames)
+	table := newRowsTable(writer, columnNames)
 
 	for i := 0; i < number; i++ {
 		row, err := reader.Read()
@@ -87,3 +83,14 @@ func head(reader csv.CsvReader, number int, writer io.Writer) error {
 	table.Render()
 	return nil
 }
+
+func newRowsTable(writer io.Writer, columnNames []string) *tablewriter.Table {
+
+	table := tablewriter.NewWriter(writer)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader(columnNames)
+
+	return table
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newInferCmd() *cobra.Command {
+
+	inferCmd := &cobra.Command{
+		Use:   "infer",
+		Short: "Infer schema",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			maxRows, _ := cmd.Flags().GetInt("rows")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			if maxRows < 0 {
+				return fmt.Errorf("rows must be greater than or equal to 0")
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runInfer(
+				format,
+				inputPath,
+				maxRows,
+				outputPath)
+		},
+	}
+
+	inferCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	inferCmd.MarkFlagRequired("input")
+	inferCmd.Flags().IntP("rows", "r", 0, "(optional) Number of rows to scan. If not specified, all rows are scanned.")
+	inferCmd.Flags().StringP("output", "o", "", "Output schema file path. JSON or YAML(.yaml, .yml) can be used.")
+	inferCmd.MarkFlagRequired("output")
+
+	return inferCmd
+}
+
+func runInfer(format csv.Format, inputPath string, maxRows int, outputPath string) error {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	schema, err := csv.InferSchema(reader, maxRows)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the CSV file")
+	}
+
+	return csv.SaveSchema(schema, outputPath)
+}
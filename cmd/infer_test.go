@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestInferCmd(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Price,Active,Date,Note",
+		"1,Yamada,100,true,2022-01-02,",
+		"2,Sato,1.5,FALSE,2022-01-03,x",
+		"3,Sato,,true,,x",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := `{
+  "columns": [
+    {
+      "name": "ID",
+      "type": "integer",
+      "required": true,
+      "notNull": true,
+      "unique": true,
+      "maxLength": 1,
+      "nullRatio": 0
+    },
+    {
+      "name": "Name",
+      "type": "string",
+      "required": true,
+      "notNull": true,
+      "maxLength": 6,
+      "nullRatio": 0
+    },
+    {
+      "name": "Price",
+      "type": "decimal",
+      "required": true,
+      "unique": true,
+      "maxLength": 3,
+      "nullRatio": 0.3333333333333333
+    },
+    {
+      "name": "Active",
+      "type": "boolean",
+      "required": true,
+      "notNull": true,
+      "maxLength": 5,
+      "nullRatio": 0
+    },
+    {
+      "name": "Date",
+      "type": "date",
+      "layout": "2006-01-02",
+      "required": true,
+      "unique": true,
+      "maxLength": 10,
+      "nullRatio": 0.3333333333333333
+    },
+    {
+      "name": "Note",
+      "type": "string",
+      "required": true,
+      "maxLength": 1,
+      "nullRatio": 0.3333333333333333
+    }
+  ]
+}
+`
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestInferCmd_yaml(t *testing.T) {
+
+	s := joinRows(
+		"ID\tDate",
+		"1\t2022/01/02",
+		"2\t2022/01/03",
+		"x\t",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+	os.Rename(fo, fo+".yaml")
+	defer os.Remove(fo + ".yaml")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", fi,
+		"-o", fo + ".yaml",
+		"--delim", `\t`,
+		"--rows", "2", // 3行目は対象外
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo+".yaml")
+
+	expect := `columns:
+  - name: ID
+    type: integer
+    required: true
+    notNull: true
+    unique: true
+    maxLength: 1
+    nullRatio: 0
+  - name: Date
+    type: date
+    layout: 2006/01/02
+    required: true
+    notNull: true
+    unique: true
+    maxLength: 10
+    nullRatio: 0
+`
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestInferCmd_validate(t *testing.T) {
+
+	// 推定したスキーマで検証できること
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, "")
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", fi,
+		"-o", fs,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestInferCmd_invalidRows(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", "input.csv",
+		"-o", "output.json",
+		"--rows", "-1",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "rows must be greater than or equal to 0" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestInferCmd_inputFileNotFound(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", fi + "____", // 存在しないファイル
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	pathErr := err.(*os.PathError)
+	if pathErr.Path != fi+"____" || pathErr.Op != "open" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestInferCmd_inputFileEmpty(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"infer",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to read the CSV file: EOF" {
+		t.Fatal("failed test\n", err)
+	}
+}
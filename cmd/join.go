@@ -8,7 +8,6 @@ import (
 	"github.com/onozaty/csvt/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"golang.org/x/exp/slices"
 )
 
 func newJoinCmd() *cobra.Command {
@@ -31,9 +30,12 @@ func newJoinCmd() *cobra.Command {
 			secondJoinColumnName, _ := cmd.Flags().GetString("column-second")
 			useFileTable, _ := cmd.Flags().GetBool("usingfile")
 			noRecordNoError, _ := cmd.Flags().GetBool("norecord")
+			// 出力を圧縮する場合は、一時ファイルも圧縮する
+			compressFileTable := getOutputCompression(outputPath, format) != csv.CompressionNone
 			joinOptions := JoinOptions{
 				secondJoinColumnName: secondJoinColumnName,
 				useFileTable:         useFileTable,
+				compressFileTable:    compressFileTable,
 				noRecordNoError:      noRecordNoError,
 			}
 
@@ -62,6 +64,7 @@ func newJoinCmd() *cobra.Command {
 type JoinOptions struct {
 	secondJoinColumnName string
 	useFileTable         bool
+	compressFileTable    bool
 	noRecordNoError      bool
 }
 
@@ -90,7 +93,12 @@ func runJoin(format csv.Format, firstPath string, secondPath string, joinColumnN
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return outputClose()
 }
 
 func join(first csv.CsvReader, second csv.CsvReader, joinColumnName string, writer csv.CsvWriter, options JoinOptions) error {
@@ -105,7 +113,7 @@ func join(first csv.CsvReader, second csv.CsvReader, joinColumnName string, writ
 	var err error
 
 	if options.useFileTable {
-		secondTable, err = csv.LoadCsvFileTable(second, secondJoinColumnName)
+		secondTable, err = csv.LoadCsvFileTable(second, secondJoinColumnName, options.compressFileTable)
 	} else {
 		secondTable, err = csv.LoadCsvMemoryTable(second, secondJoinColumnName)
 	}
@@ -118,13 +126,13 @@ func join(first csv.CsvReader, second csv.CsvReader, joinColumnName string, writ
 	if err != nil {
 		return errors.Wrap(err, "failed to read the first CSV file")
 	}
-	firstJoinColumnIndex := slices.Index(firstColumnNames, firstJoinColumnName)
-	if firstJoinColumnIndex == -1 {
-		return fmt.Errorf("missing %s in the first CSV file", firstJoinColumnName)
+	firstJoinColumnIndex, err := findColumnIndex(firstColumnNames, firstJoinColumnName, "first CSV file")
+	if err != nil {
+		return err
 	}
 
 	// 追加するものは、結合用のカラムを除く
-	appendsecondColumnNames := util.Remove(secondTable.ColumnNames(), secondJoinColumnName)
+	appendsecondColumnNames := util.Remove(secondTable.ColumnNames(), secondTable.KeyColumnName())
 	outColumnNames := append(firstColumnNames, appendsecondColumnNames...)
 	err = writer.Write(outColumnNames)
 	if err != nil {
@@ -149,6 +149,56 @@ func TestJoinCmd_usingfile(t *testing.T) {
 	}
 }
 
+func TestJoinCmd_usingfileCompressed(t *testing.T) {
+
+	s1 := `ID,Name,CompanyID
+1,Yamada,1
+5,Ichikawa,1
+2,"Hanako, Sato",3
+`
+	f1 := createCompressedFile(t, s1, csv.CompressionGzip)
+	defer os.Remove(f1)
+
+	s2 := `CompanyID,CompanyName
+1,CompanyA
+2,CompanyB
+3,会社C
+`
+	f2 := createCompressedFile(t, s2, csv.CompressionZstd)
+	defer os.Remove(f2)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	fo := filepath.Join(d, "output.csv.bz2")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"join",
+		"-1", f1,
+		"-2", f2,
+		"-o", fo,
+		"-c", "CompanyID",
+		"--usingfile",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDecompressedString(t, fo)
+
+	expect := "ID,Name,CompanyID,CompanyName\r\n" +
+		"1,Yamada,1,CompanyA\r\n" +
+		"5,Ichikawa,1,CompanyA\r\n" +
+		"2,\"Hanako, Sato\",3,会社C\r\n"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
 func TestJoinCmd_invalidFormat(t *testing.T) {
 
 	f1 := createTempFile(t, "")
@@ -539,3 +589,25 @@ func TestJoin_secondFileEmpty(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestJoin_secondFileDuplicateHeader(t *testing.T) {
+
+	s1 := `ID,Name,CompanyID
+1,Yamada,1
+`
+	r1 := csv.NewCsvReader(strings.NewReader(s1), csv.Format{})
+
+	s2 := `CompanyID,Name,Name
+1,CompanyA,A
+`
+	r2 := csv.NewCsvReader(strings.NewReader(s2), csv.Format{})
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	out := csv.NewCsvWriter(w, csv.Format{})
+
+	err := join(r1, r2, "CompanyID", out, JoinOptions{})
+	if err == nil || err.Error() != "failed to read the second CSV file: duplicate column names in the header: Name (use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	_ "modernc.org/sqlite"
+)
+
+func newQueryCmd() *cobra.Command {
+
+	queryCmd := &cobra.Command{
+		Use:   "query SQL",
+		Short: "Run SQL query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			tableSpecs, _ := cmd.Flags().GetStringArray("table")
+			outputPath, _ := cmd.Flags().GetString("output")
+			allText, _ := cmd.Flags().GetBool("text")
+
+			tables := []queryTable{}
+			for _, spec := range tableSpecs {
+				name, path, found := strings.Cut(spec, "=")
+				if !found || name == "" || path == "" {
+					return fmt.Errorf("table should be specified in the format NAME=PATH: %s", spec)
+				}
+				tables = append(tables, queryTable{name: name, path: path})
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runQuery(
+				format,
+				args[0],
+				tables,
+				allText,
+				outputPath,
+				cmd.OutOrStdout())
+		},
+	}
+
+	queryCmd.Flags().StringArrayP("table", "t", []string{}, "(optional) CSV file to use as a table, in the format NAME=PATH.\nCSV files written directly after FROM or JOIN in the query are also loaded as tables.")
+	queryCmd.Flags().StringP("output", "o", "", "(optional) Output CSV file path. If not specified, it will be output to the standard output.")
+	queryCmd.Flags().BoolP("text", "", false, "(optional) Load all columns as text. By default, integer and decimal columns are loaded as numbers.")
+
+	return queryCmd
+}
+
+type queryTable struct {
+	name string
+	path string
+}
+
+func runQuery(format csv.Format, query string, tables []queryTable, allText bool, outputPath string, stdout io.Writer) error {
+
+	query, fileTables := resolveQueryTables(query, tables)
+	tables = append(tables, fileTables...)
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// インメモリのDBは接続ごとに別になるので、1接続に限定
+	db.SetMaxOpenConns(1)
+
+	for _, table := range tables {
+		if err := loadQueryTable(db, format, table, allText); err != nil {
+			return err
+		}
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute the query")
+	}
+	defer rows.Close()
+
+	var writer csv.CsvWriter
+	outputClose := func() error { return nil }
+	if outputPath != "" {
+		outputWriter, close, err := setupOutput(outputPath, format)
+		if err != nil {
+			return err
+		}
+		defer close()
+		writer = outputWriter
+		outputClose = close
+	} else {
+		writer = csv.NewCsvWriter(stdout, format)
+	}
+
+	if err := writeQueryRows(rows, writer); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	return outputClose()
+}
+
+func loadQueryTable(db *sql.DB, format csv.Format, table queryTable, allText bool) error {
+
+	// 型を決めるために一度読み込む
+	reader, close, err := setupInput(table.path, format)
+	if err != nil {
+		return err
+	}
+	schema, err := csv.InferSchema(reader, 0)
+	close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the CSV file %s", table.path)
+	}
+
+	// 同じ名前のカラムは作れないので一意に
+	columnNames := []string{}
+	for _, column := range schema.Columns {
+		columnNames = append(columnNames, column.Name)
+	}
+	columnNames = csv.DedupeColumnNames(columnNames)
+
+	numeric := []bool{}
+	definitions := []string{}
+	placeholders := []string{}
+	for i, column := range schema.Columns {
+		columnType := "TEXT"
+		// 先頭が0の数値(00123など)は、推定の時点で文字列になる
+		if !allText {
+			switch column.Type {
+			case csv.TypeInteger:
+				columnType = "INTEGER"
+			case csv.TypeDecimal:
+				columnType = "REAL"
+			}
+		}
+		numeric = append(numeric, columnType != "TEXT")
+		definitions = append(definitions, quoteQueryIdentifier(columnNames[i])+" "+columnType)
+		placeholders = append(placeholders, "?")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("CREATE TABLE " + quoteQueryIdentifier(table.name) + " (" + strings.Join(definitions, ", ") + ")"); err != nil {
+		return errors.Wrapf(err, "failed to create the table %s", table.name)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO " + quoteQueryIdentifier(table.name) + " VALUES (" + strings.Join(placeholders, ", ") + ")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	reader, close, err = setupInput(table.path, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	// ヘッダは読み飛ばす
+	if _, err := reader.Read(); err != nil {
+		return err
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		values := make([]interface{}, len(numeric))
+		for i := range numeric {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			if numeric[i] && value == "" {
+				// 数値のカラムの空はNULLに
+				values[i] = nil
+			} else {
+				values[i] = value
+			}
+		}
+
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func writeQueryRows(rows *sql.Rows, writer csv.CsvWriter) error {
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err := writer.Write(columnNames); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columnNames))
+	pointers := make([]interface{}, len(columnNames))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		record := []string{}
+		for _, value := range values {
+			record = append(record, queryValueString(value))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func queryValueString(value interface{}) string {
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// FROMやJOINの直後に書かれたCSVファイルのパスをテーブル名に置き換え、読み込むテーブルとして返す
+func resolveQueryTables(query string, tables []queryTable) (string, []queryTable) {
+
+	names := map[string]bool{}
+	for _, table := range tables {
+		names[strings.ToLower(table.name)] = true
+	}
+
+	pathTables := map[string]string{}
+	fileTables := []queryTable{}
+
+	resolve := func(reference string) (string, bool) {
+
+		if names[strings.ToLower(reference)] {
+			// --tableで指定されたもの
+			return "", false
+		}
+		if name, ok := pathTables[reference]; ok {
+			return name, true
+		}
+
+		// 存在するファイル、もしくはデータファイルの拡張子を持つもの(CTEやschema.tableなどと区別するため)
+		if info, err := os.Stat(reference); (err != nil || !info.Mode().IsRegular()) && !hasQueryDataFileExtension(reference) {
+			return "", false
+		}
+
+		name := uniqueQueryTableName(queryTableName(reference), names)
+		names[strings.ToLower(name)] = true
+		pathTables[reference] = name
+		fileTables = append(fileTables, queryTable{name: name, path: reference})
+
+		return name, true
+	}
+
+	b := strings.Builder{}
+	runes := []rune(query)
+	expectTable := false
+	inFromList := false
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\'' && !expectTable:
+			// 文字列リテラルはそのまま
+			end := scanQueryQuoted(runes, i, '\'')
+			b.WriteString(string(runes[i:end]))
+			i = end
+			continue
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			b.WriteString(string(runes[i:end]))
+			i = end
+			continue
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := i + 2
+			for end < len(runes) && !(runes[end-1] == '*' && runes[end] == '/' && end > i+2) {
+				end++
+			}
+			if end < len(runes) {
+				end++
+			}
+			b.WriteString(string(runes[i:end]))
+			i = end
+			continue
+
+		case isQuerySpace(r):
+			b.WriteRune(r)
+			i++
+			continue
+		}
+
+		if expectTable && r != '(' {
+			expectTable = false
+
+			var reference string
+			var end int
+			if r == '"' || r == '`' || r == '[' || r == '\'' {
+				closing := r
+				if r == '[' {
+					closing = ']'
+				}
+				end = scanQueryQuoted(runes, i, closing)
+				reference = unquoteQueryIdentifier(string(runes[i:end]))
+			} else {
+				end = i
+				for end < len(runes) && !isQuerySpace(runes[end]) && !strings.ContainsRune(",;()", runes[end]) {
+					end++
+				}
+				reference = string(runes[i:end])
+			}
+
+			name, replaced := resolve(reference)
+			if replaced {
+				b.WriteString(quoteQueryIdentifier(name))
+			} else {
+				b.WriteString(string(runes[i:end]))
+			}
+			i = end
+			inFromList = true
+			continue
+		}
+
+		if isQueryWordRune(r) {
+			end := i
+			for end < len(runes) && isQueryWordRune(runes[end]) {
+				end++
+			}
+			word := strings.ToUpper(string(runes[i:end]))
+			switch word {
+			case "FROM", "JOIN":
+				expectTable = true
+			case "WHERE", "GROUP", "ORDER", "LIMIT", "HAVING", "UNION", "EXCEPT", "INTERSECT", "ON", "USING", "WINDOW", "SELECT":
+				inFromList = false
+			}
+			b.WriteString(string(runes[i:end]))
+			i = end
+			continue
+		}
+
+		if r == ',' && inFromList {
+			// FROM a.csv, b.csv のような指定
+			expectTable = true
+		}
+		if r == '(' || r == ')' {
+			inFromList = false
+		}
+
+		b.WriteRune(r)
+		i++
+	}
+
+	return b.String(), fileTables
+}
+
+// 引用符で囲まれた部分の終わりの位置 (引用符を重ねたものはエスケープとみなす)
+func scanQueryQuoted(runes []rune, start int, closing rune) int {
+
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == closing {
+			if closing != ']' && i+1 < len(runes) && runes[i+1] == closing {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+func unquoteQueryIdentifier(quoted string) string {
+
+	runes := []rune(quoted)
+	if len(runes) < 2 {
+		return quoted
+	}
+
+	open := runes[0]
+	inner := string(runes[1 : len(runes)-1])
+	if open == '[' {
+		return inner
+	}
+	return strings.ReplaceAll(inner, string(open)+string(open), string(open))
+}
+
+func quoteQueryIdentifier(name string) string {
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// テーブルとして読み込むファイルの拡張子
+var queryDataFileExtensions = []string{".csv", ".tsv", ".txt", ".dat", ".json", ".ndjson", ".jsonl", ".xlsx", ".parquet"}
+
+// データファイルの拡張子を持つか (圧縮の拡張子やアーカイブ内のファイルも考慮)
+func hasQueryDataFileExtension(path string) bool {
+
+	if _, member, ok := csv.SplitArchivePath(path); ok {
+		path = member
+	}
+	path, _ = csv.SplitCompressionExtension(path)
+
+	return slices.Contains(queryDataFileExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// ファイル名(拡張子を除く)をテーブル名に
+func queryTableName(path string) string {
+
+	base := filepath.Base(path)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if name == "" {
+		return "table"
+	}
+	return name
+}
+
+func uniqueQueryTableName(name string, names map[string]bool) string {
+
+	if !names[strings.ToLower(name)] {
+		return name
+	}
+
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s_%d", name, n)
+		if !names[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
+
+func isQuerySpace(r rune) bool {
+
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+func isQueryWordRune(r rune) bool {
+
+	return r == '_' || r == '$' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') || r > 127
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestQueryCmd(t *testing.T) {
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	sales := filepath.Join(d, "sales.csv")
+	if err := os.WriteFile(sales, []byte(joinRows(
+		"id,store,amount",
+		"1,10,100",
+		"2,20,50.5",
+		"3,10,",
+		"4,20,30",
+	)), 0644); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	stores := filepath.Join(d, "stores.csv")
+	if err := os.WriteFile(stores, []byte(joinRows(
+		"id,region",
+		"10,East",
+		"20,West",
+	)), 0644); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT region, SUM(amount) AS total FROM " + sales + " s JOIN " + stores + " t ON s.store = t.id GROUP BY region ORDER BY region",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := joinRows(
+		"region,total",
+		"East,100",
+		"West,80.5",
+	)
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestQueryCmd_table(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Code",
+		"1,Yamada,007",
+		"2,,010",
+		"10,Sato,100",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	o := filepath.Join(d, "output.csv")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT ID, Name, Code FROM users WHERE ID > 1 AND Name = '' OR Code = 100 ORDER BY ID",
+		"-t", "users=" + f,
+		"-o", o,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, o)
+
+	// 空の文字列はNULLにはならない (先頭が0の値を含むCodeはテキスト)
+	expect := joinRows(
+		"ID,Name,Code",
+		"2,,010",
+		"10,Sato,100",
+	)
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestQueryCmd_text(t *testing.T) {
+
+	s := joinRows(
+		"ID,Code",
+		"1,007",
+		"2,10",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT * FROM codes ORDER BY Code",
+		"-t", "codes=" + f,
+		"--text",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := joinRows(
+		"ID,Code",
+		"1,007",
+		"2,10",
+	)
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestQueryCmd_leadingZero(t *testing.T) {
+
+	s := joinRows(
+		"ID,Code,Amount",
+		"1,007,0.5",
+		"2,10,-0.25",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT ID, Code, Amount * 2 AS Amount FROM codes ORDER BY ID",
+		"-t", "codes=" + f,
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	// 先頭が0の整数はテキストのまま (0.5 などの小数は数値)
+	expect := joinRows(
+		"ID,Code,Amount",
+		"1,007,1",
+		"2,10,-0.5",
+	)
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestQueryCmd_format(t *testing.T) {
+
+	s := "ID\tName|1\tYamada|2\tSato"
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT Name FROM " + f + " WHERE ID = 2",
+		"--delim", "\t",
+		"--sep", "|",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	if result != "Name|Sato|" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestQueryCmd_invalidTable(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT 1",
+		"-t", "users",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "table should be specified in the format NAME=PATH: users" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestQueryCmd_invalidQuery(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT * FROM users",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to execute the query: SQL logic error: no such table: users (1)" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestQueryCmd_fileNotFound(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"query",
+		"SELECT * FROM not_found.csv",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "open not_found.csv: no such file or directory" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestResolveQueryTables(t *testing.T) {
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	a := filepath.Join(d, "a.csv")
+	if err := os.WriteFile(a, []byte("x"), 0644); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	query, tables := resolveQueryTables(
+		"WITH w AS (SELECT 'FROM "+a+"' AS s /* FROM "+a+" */) SELECT * FROM "+a+", \""+filepath.Join(d, "b.csv")+"\" JOIN w JOIN a ON 1 -- FROM "+a,
+		[]queryTable{{name: "A", path: "other.csv"}})
+
+	expect := "WITH w AS (SELECT 'FROM " + a + "' AS s /* FROM " + a + " */) SELECT * FROM \"a_2\", \"b\" JOIN w JOIN a ON 1 -- FROM " + a
+	if query != expect {
+		t.Fatal("failed test\n", query)
+	}
+
+	expectTables := []queryTable{
+		{name: "a_2", path: a},
+		{name: "b", path: filepath.Join(d, "b.csv")},
+	}
+	if !reflect.DeepEqual(tables, expectTables) {
+		t.Fatal("failed test\n", tables)
+	}
+}
+
+func TestResolveQueryTables_notPath(t *testing.T) {
+
+	query, tables := resolveQueryTables(
+		"SELECT * FROM main.sqlite_master JOIN schema.t JOIN 'data/z.csv' z JOIN 'w' JOIN x",
+		[]queryTable{})
+
+	// データファイルの拡張子が無く、存在もしないものはテーブル名のまま
+	expect := "SELECT * FROM main.sqlite_master JOIN schema.t JOIN \"z\" z JOIN 'w' JOIN x"
+	if query != expect {
+		t.Fatal("failed test\n", query)
+	}
+
+	expectTables := []queryTable{
+		{name: "z", path: "data/z.csv"},
+	}
+	if !reflect.DeepEqual(tables, expectTables) {
+		t.Fatal("failed test\n", tables)
+	}
+}
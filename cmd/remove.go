@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/onozaty/csvt/csv"
@@ -61,7 +60,12 @@ func runRemove(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func remove(reader csv.CsvReader, removeColumnNames []string, writer csv.CsvWriter) error {
@@ -72,15 +76,9 @@ func remove(reader csv.CsvReader, removeColumnNames []string, writer csv.CsvWrit
 		return errors.Wrap(err, "failed to read the CSV file")
 	}
 
-	removeColumnIndexes := []int{}
-	for _, removeColumnName := range removeColumnNames {
-
-		removeColumnIndex := slices.Index(columnNames, removeColumnName)
-		if removeColumnIndex == -1 {
-			return fmt.Errorf("missing %s in the CSV file", removeColumnName)
-		}
-
-		removeColumnIndexes = append(removeColumnIndexes, removeColumnIndex)
+	removeColumnIndexes, err := getTargetColumnsIndexes(columnNames, removeColumnNames)
+	if err != nil {
+		return err
 	}
 
 	// 指定したカラム以外に絞るフィルタを定義
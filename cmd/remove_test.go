@@ -218,3 +218,41 @@ func TestRemoveCmd_invalidFormat(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestRemoveCmd_selector(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,price_a,price_b,Note",
+		"1,Yamada,10,20,x",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"remove",
+		"-i", fi,
+		"-o", fo,
+		"-c", "2-4",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"ID,Note",
+		"1,x",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/onozaty/csvt/csv"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"golang.org/x/exp/slices"
 )
 
 func newRenameCmd() *cobra.Command {
@@ -69,7 +68,12 @@ func runRename(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func rename(reader csv.CsvReader, targetColumnNames []string, afterColumnNames []string, writer csv.CsvWriter) error {
@@ -85,9 +89,9 @@ func rename(reader csv.CsvReader, targetColumnNames []string, afterColumnNames [
 	targetColumnIndexes := []int{}
 	for _, targetColumnName := range targetColumnNames {
 
-		targetColumnIndex := slices.Index(columnNames, targetColumnName)
-		if targetColumnIndex == -1 {
-			return fmt.Errorf("missing %s in the CSV file", targetColumnName)
+		targetColumnIndex, err := getTargetColumnIndex(columnNames, targetColumnName)
+		if err != nil {
+			return err
 		}
 
 		targetColumnIndexes = append(targetColumnIndexes, targetColumnIndex)
@@ -75,7 +75,12 @@ func runReplace(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func replace(reader csv.CsvReader, targetColumnNames []string, regex *regexp.Regexp, replacement string, writer csv.CsvWriter) error {
@@ -22,6 +22,32 @@ func newRootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().BoolP("allquote", "", false, "(optional) Always quote CSV fields. The default is to quote only the necessary fields.")
 	rootCmd.PersistentFlags().StringP("encoding", "", "", "(optional) CSV encoding. The default is utf-8.")
 	rootCmd.PersistentFlags().BoolP("bom", "", false, "(optional) CSV with BOM. When reading, the BOM will be automatically removed without this flag.")
+	rootCmd.PersistentFlags().VarP(&onErrorFlagValue{}, "on-error", "", "(optional) Behavior for records with the wrong number of fields or broken quotes.\n"+
+		"fail: stop with an error, skip: skip the record, pad: fill missing fields with empty values, log=FILE: skip the record and write to FILE.\n"+
+		"The default is fail.")
+	rootCmd.PersistentFlags().BoolP("no-header", "", false, "(optional) CSV without header. The column numbers (1, 2, ...) are used as the column names.")
+	rootCmd.PersistentFlags().BoolP("no-output-header", "", false, "(optional) Do not output the header.")
+	rootCmd.PersistentFlags().BoolP("dedupe-headers", "", false, "(optional) Rename duplicate column names in the header with a sequence number (name, name_2, ...).")
+	rootCmd.PersistentFlags().StringP("output-format", "", "", "(optional) Output format. csv, json, ndjson, xlsx, parquet, fixed, markdown, html or text can be specified. The default is csv.\n"+
+		"For json and ndjson, each record is output as an object with the column names as keys.")
+	rootCmd.PersistentFlags().BoolP("infer-types", "", false, "(optional) Infer the types of values for json, ndjson, xlsx and parquet output.\n"+
+		"Numbers and booleans are output as typed values, and empty values as null.")
+	rootCmd.PersistentFlags().StringP("input-format", "", "", "(optional) Input format. csv, json, ndjson, xlsx, parquet or fixed can be specified. The default is csv.\n"+
+		"For json and ndjson, the keys of the objects are used as the column names. Nested objects are expanded with dotted keys (a.b).")
+	rootCmd.PersistentFlags().StringP("json-array", "", "", "(optional) How to handle arrays in json and ndjson input.\n"+
+		"json: keep as a JSON string, index: expand to columns with the index (a.0, a.1, ...), join: join the elements with commas.\n"+
+		"The default is json.")
+	rootCmd.PersistentFlags().StringP("sheet", "", "", "(optional) Sheet name or number (1, 2, ...) for xlsx input. The default is the first sheet.")
+	rootCmd.PersistentFlags().StringP("parquet-schema", "", "", "(optional) Schema file (JSON or YAML) to declare the column types for parquet output.\n"+
+		"Without this, all columns are strings, or the types are inferred from all rows with --infer-types.")
+	rootCmd.PersistentFlags().StringP("fixed-spec", "", "", "(optional) Column spec file (JSON or YAML) for fixed format. The start and length of each column are in bytes of the encoding.")
+	rootCmd.PersistentFlags().IntP("table-max-width", "", 0, "(optional) Maximum display width of each cell for markdown, html and text output. Longer values are truncated. The default is no limit.")
+	rootCmd.PersistentFlags().IntP("table-page-size", "", 0, "(optional) Number of rows per table for markdown and text output. The column widths are aligned within each page. The default is all rows.")
+	rootCmd.PersistentFlags().StringP("compress", "", "", "(optional) Compression of output files. gzip, zstd, bzip2, xz or none can be specified.\nThe default is determined by the extension of the output file (.gz, .zst, .bz2, .xz). Compressed input files are detected automatically.")
+	rootCmd.PersistentFlags().IntP("compress-level", "", 0, "(optional) Compression level. gzip and bzip2: 1-9, zstd: 1-22. The default is the default level of each compression.")
+	rootCmd.PersistentFlags().BoolP("recursive", "", false, "(optional) Search the input directory (or the directories of the input pattern) recursively.")
+	rootCmd.PersistentFlags().StringP("output-name", "", "{dir}/{name}{ext}", "(optional) Output file name template when the input is a pattern or directory. The output is treated as a directory.\n"+
+		"{dir}: directory relative to the input directory, {name}: input file name without the extension, {ext}: extension of the input file.")
 	rootCmd.PersistentFlags().SortFlags = false
 	rootCmd.Flags().SortFlags = false
 
@@ -45,15 +71,29 @@ func newRootCmd() *cobra.Command {
 	rootCmd.AddCommand(newSplitCmd())
 	rootCmd.AddCommand(newHeadCmd())
 	rootCmd.AddCommand(newGroupCmd())
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newInferCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newCheckCmd())
+	rootCmd.AddCommand(newViewCmd())
+	rootCmd.AddCommand(newSqlCmd())
+	rootCmd.AddCommand(newQueryCmd())
+	rootCmd.AddCommand(newSampleCmd())
+	rootCmd.AddCommand(newTailCmd())
 
 	for _, c := range rootCmd.Commands() {
-		// フラグ以外は受け付けないように
-		c.Args = func(cmd *cobra.Command, args []string) error {
-			if len(args) > 0 {
-				return fmt.Errorf("only flags can be specified")
+		// フラグ以外は受け付けないように (引数を取るコマンドは個別に指定)
+		if c.Args == nil {
+			c.Args = func(cmd *cobra.Command, args []string) error {
+				if len(args) > 0 {
+					return fmt.Errorf("only flags can be specified")
+				}
+				return nil
 			}
-			return nil
 		}
+		enableBatch(c)
+		// バッチ処理でも一度だけ開くように、バッチ処理の外側で
+		enableOnErrorLog(c)
 		c.Flags().SortFlags = false
 		c.InheritedFlags().SortFlags = false
 	}
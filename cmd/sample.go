@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	_sort "sort"
+	"time"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newSampleCmd() *cobra.Command {
+
+	sampleCmd := &cobra.Command{
+		Use:   "sample",
+		Short: "Extract random sample rows",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			outputPath, _ := cmd.Flags().GetString("output")
+			rate, _ := cmd.Flags().GetFloat64("rate")
+			number, _ := cmd.Flags().GetInt("number")
+			seed, _ := cmd.Flags().GetInt64("seed")
+			byColumnName, _ := cmd.Flags().GetString("by")
+
+			useRate := cmd.Flags().Changed("rate")
+			useNumber := cmd.Flags().Changed("number")
+
+			if useRate && useNumber {
+				return fmt.Errorf("not allowed to specify both --rate and --number")
+			}
+			if !useRate && !useNumber {
+				return fmt.Errorf("either --rate or --number must be specified")
+			}
+			if useRate && (rate <= 0 || rate > 1) {
+				return fmt.Errorf("rate must be greater than 0 and less than or equal to 1")
+			}
+			if useNumber && number <= 0 {
+				return fmt.Errorf("number must be greater than or equal to 1")
+			}
+
+			if !cmd.Flags().Changed("seed") {
+				// 指定が無い場合は毎回異なる結果に
+				seed = time.Now().UnixNano()
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runSample(
+				format,
+				inputPath,
+				SampleOptions{
+					Rate:         rate,
+					Number:       number,
+					Seed:         seed,
+					ByColumnName: byColumnName,
+				},
+				outputPath)
+		},
+	}
+
+	sampleCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	sampleCmd.MarkFlagRequired("input")
+	sampleCmd.Flags().StringP("output", "o", "", "Output CSV file path.")
+	sampleCmd.MarkFlagRequired("output")
+	sampleCmd.Flags().Float64P("rate", "r", 0, "(optional) Rate of rows to extract (0 < rate <= 1). Each row is extracted with this probability.")
+	sampleCmd.Flags().IntP("number", "n", 0, "(optional) Number of rows to extract. The rows are selected at random in a single pass.")
+	sampleCmd.Flags().Int64P("seed", "", 0, "(optional) Seed of the random numbers. The same seed gives the same result. If not specified, the result is different every time.")
+	sampleCmd.Flags().StringP("by", "", "", "(optional) Name of the column to sample for each value (group). With --number, the number of rows are extracted from each group.")
+
+	return sampleCmd
+}
+
+type SampleOptions struct {
+	Rate         float64
+	Number       int
+	Seed         int64
+	ByColumnName string
+}
+
+func runSample(format csv.Format, inputPath string, options SampleOptions, outputPath string) error {
+
+	reader, writer, close, err := setupInputOutput(inputPath, outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	err = sample(reader, options, writer)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
+}
+
+// 入力の順序を保つため、行番号と合わせて保持
+type sampledRow struct {
+	index int
+	row   []string
+}
+
+func sample(reader csv.CsvReader, options SampleOptions, writer csv.CsvWriter) error {
+
+	// ヘッダ
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the CSV file")
+	}
+
+	byColumnIndex := -1
+	if options.ByColumnName != "" {
+		byColumnIndex, err = findColumnIndex(columnNames, options.ByColumnName, "CSV file")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Write(columnNames)
+	if err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+
+	// グループごとのリザーバ (件数指定時)
+	reservoirs := map[string][]sampledRow{}
+	groupCounts := map[string]int{}
+
+	index := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the CSV file")
+		}
+
+		if options.Number == 0 {
+			// 割合指定の場合は、行ごとに判定してそのまま出力
+			if random.Float64() < options.Rate {
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		group := ""
+		if byColumnIndex != -1 {
+			group = row[byColumnIndex]
+		}
+
+		// リザーバサンプリング
+		// 件数に達するまでは全て保持し、それ以降は 件数/それまでの行数 の確率で入れ替える
+		groupCounts[group]++
+		reservoir := reservoirs[group]
+		if len(reservoir) < options.Number {
+			reservoirs[group] = append(reservoir, sampledRow{index: index, row: row})
+		} else if j := random.Intn(groupCounts[group]); j < options.Number {
+			reservoir[j] = sampledRow{index: index, row: row}
+		}
+		index++
+	}
+
+	if options.Number == 0 {
+		return nil
+	}
+
+	sampled := []sampledRow{}
+	for _, reservoir := range reservoirs {
+		sampled = append(sampled, reservoir...)
+	}
+	_sort.Slice(sampled, func(i, j int) bool { return sampled[i].index < sampled[j].index })
+
+	for _, s := range sampled {
+		if err := writer.Write(s.row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
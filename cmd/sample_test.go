@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func createSampleInput(t *testing.T, count int) string {
+
+	rows := []string{"id,group"}
+	for i := 1; i <= count; i++ {
+		rows = append(rows, strconv.Itoa(i)+","+strconv.Itoa(i%3))
+	}
+
+	return createTempFile(t, joinRows(rows...))
+}
+
+func readSampleRows(t *testing.T, path string) []string {
+
+	rows := strings.Split(strings.TrimSuffix(readString(t, path), "\r\n"), "\r\n")
+	if rows[0] != "id,group" {
+		t.Fatal("failed test\n", rows)
+	}
+	return rows[1:]
+}
+
+func TestSampleCmd_number(t *testing.T) {
+
+	fi := createSampleInput(t, 100)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"-n", "10",
+		"--seed", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	rows := readSampleRows(t, fo)
+	if len(rows) != 10 {
+		t.Fatal("failed test\n", rows)
+	}
+
+	// 元の順序を保つ
+	prev := 0
+	for _, row := range rows {
+		id, _ := strconv.Atoi(strings.Split(row, ",")[0])
+		if id <= prev {
+			t.Fatal("failed test\n", rows)
+		}
+		prev = id
+	}
+}
+
+func TestSampleCmd_numberOverRows(t *testing.T) {
+
+	fi := createSampleInput(t, 3)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"-n", "10",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	expect := joinRows(
+		"id,group",
+		"1,1",
+		"2,2",
+		"3,0",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSampleCmd_seed(t *testing.T) {
+
+	fi := createSampleInput(t, 1000)
+	defer os.Remove(fi)
+
+	results := []string{}
+	for _, args := range [][]string{
+		{"-n", "20", "--seed", "42"},
+		{"-n", "20", "--seed", "42"},
+		{"-r", "0.1", "--seed", "42"},
+		{"-r", "0.1", "--seed", "42"},
+	} {
+		fo := createTempFile(t, "")
+		defer os.Remove(fo)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"sample", "-i", fi, "-o", fo}, args...))
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+
+		results = append(results, readString(t, fo))
+	}
+
+	if results[0] != results[1] || results[2] != results[3] {
+		t.Fatal("failed test\n", results)
+	}
+}
+
+func TestSampleCmd_rate(t *testing.T) {
+
+	fi := createSampleInput(t, 10000)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"--rate", "0.1",
+		"--seed", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 確率的なので、おおよその件数で確認
+	rows := readSampleRows(t, fo)
+	if len(rows) < 800 || len(rows) > 1200 {
+		t.Fatal("failed test\n", len(rows))
+	}
+}
+
+func TestSampleCmd_rateAll(t *testing.T) {
+
+	fi := createSampleInput(t, 5)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"--rate", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	rows := readSampleRows(t, fo)
+	if len(rows) != 5 {
+		t.Fatal("failed test\n", rows)
+	}
+}
+
+func TestSampleCmd_by(t *testing.T) {
+
+	fi := createSampleInput(t, 30)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"-n", "2",
+		"--by", "group",
+		"--seed", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	rows := readSampleRows(t, fo)
+	if len(rows) != 6 {
+		t.Fatal("failed test\n", rows)
+	}
+
+	groupCounts := map[string]int{}
+	for _, row := range rows {
+		groupCounts[strings.Split(row, ",")[1]]++
+	}
+	if groupCounts["0"] != 2 || groupCounts["1"] != 2 || groupCounts["2"] != 2 {
+		t.Fatal("failed test\n", rows)
+	}
+}
+
+func TestSampleCmd_byColumnNotFound(t *testing.T) {
+
+	fi := createSampleInput(t, 3)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", fi,
+		"-o", fo,
+		"-n", "1",
+		"--by", "xxx",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "missing xxx in the CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSampleCmd_invalidArgs(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"-r", "0.1", "-n", "1"}, "not allowed to specify both --rate and --number"},
+		{[]string{}, "either --rate or --number must be specified"},
+		{[]string{"-r", "0"}, "rate must be greater than 0 and less than or equal to 1"},
+		{[]string{"-r", "1.5"}, "rate must be greater than 0 and less than or equal to 1"},
+		{[]string{"-n", "0"}, "number must be greater than or equal to 1"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"sample", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestSampleCmd_inputFileNotFound(t *testing.T) {
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sample",
+		"-i", "not-found.csv",
+		"-o", fo,
+		"-n", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "open not-found.csv: no such file or directory" {
+		t.Fatal("failed test\n", err)
+	}
+}
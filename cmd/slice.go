@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"math"
+	"os"
+	"regexp"
 
 	"github.com/onozaty/csvt/csv"
+	"github.com/onozaty/csvt/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +29,11 @@ func newSliceCmd() *cobra.Command {
 			inputPath, _ := cmd.Flags().GetString("input")
 			start, _ := cmd.Flags().GetInt("start")
 			end, _ := cmd.Flags().GetInt("end")
+			fromByteValue, _ := cmd.Flags().GetString("from-byte")
+			toByteValue, _ := cmd.Flags().GetString("to-byte")
+			columnName, _ := cmd.Flags().GetString("column")
+			startWhenValue, _ := cmd.Flags().GetString("start-when")
+			stopWhenValue, _ := cmd.Flags().GetString("stop-when")
 			outputPath, _ := cmd.Flags().GetString("output")
 
 			// 開始は1以上
@@ -37,14 +46,63 @@ func newSliceCmd() *cobra.Command {
 				return fmt.Errorf("end must be greater than or equal to start")
 			}
 
+			options := SliceOptions{
+				Start:      start,
+				End:        end,
+				FromByte:   0,
+				ToByte:     -1,
+				ColumnName: columnName,
+			}
+
+			if fromByteValue != "" || toByteValue != "" {
+				if cmd.Flags().Changed("start") || cmd.Flags().Changed("end") {
+					return fmt.Errorf("start and end cannot be specified with from-byte or to-byte")
+				}
+
+				if fromByteValue != "" {
+					options.FromByte, err = util.ParseByteSize(fromByteValue)
+					if err != nil {
+						return errors.Wrap(err, "invalid from-byte")
+					}
+				}
+				if toByteValue != "" {
+					options.ToByte, err = util.ParseByteSize(toByteValue)
+					if err != nil {
+						return errors.Wrap(err, "invalid to-byte")
+					}
+					if options.ToByte < options.FromByte {
+						return fmt.Errorf("to-byte must be greater than or equal to from-byte")
+					}
+				}
+			}
+
+			if (startWhenValue != "" || stopWhenValue != "") && columnName == "" {
+				return fmt.Errorf("column is required for start-when and stop-when")
+			}
+			if columnName != "" && startWhenValue == "" && stopWhenValue == "" {
+				return fmt.Errorf("column can only be used with start-when or stop-when")
+			}
+
+			if startWhenValue != "" {
+				options.StartWhen, err = regexp.Compile(startWhenValue)
+				if err != nil {
+					return errors.WithMessage(err, "regular expression specified in --start-when is invalid")
+				}
+			}
+			if stopWhenValue != "" {
+				options.StopWhen, err = regexp.Compile(stopWhenValue)
+				if err != nil {
+					return errors.WithMessage(err, "regular expression specified in --stop-when is invalid")
+				}
+			}
+
 			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
 			cmd.SilenceUsage = true
 
 			return runSlice(
 				format,
 				inputPath,
-				start,
-				end,
+				options,
 				outputPath)
 		},
 	}
@@ -53,43 +111,99 @@ func newSliceCmd() *cobra.Command {
 	sliceCmd.MarkFlagRequired("input")
 	sliceCmd.Flags().IntP("start", "s", 1, "The number of the starting row. If not specified, it will be the first row.")
 	sliceCmd.Flags().IntP("end", "e", math.MaxInt32, "The number of the end row. If not specified, it will be the last row.")
+	sliceCmd.Flags().StringP("from-byte", "", "", "(optional) Byte offset of the file to start from. The rows starting at or after the offset are output.\n"+
+		"K, M and G (1024-based) can be used as units. Only for uncompressed UTF-8 CSV files.")
+	sliceCmd.Flags().StringP("to-byte", "", "", "(optional) Byte offset of the file to end. The rows starting before the offset are output.")
+	sliceCmd.Flags().StringP("column", "c", "", "(optional) Name of the column for --start-when and --stop-when.")
+	sliceCmd.Flags().StringP("start-when", "", "", "(optional) Regular expression to start. The output starts from the first row whose value of the column matches.")
+	sliceCmd.Flags().StringP("stop-when", "", "", "(optional) Regular expression to stop. The output stops before the first row whose value of the column matches, and the rest is not read.")
 	sliceCmd.Flags().StringP("output", "o", "", "Output CSV file path.")
 	sliceCmd.MarkFlagRequired("output")
 
 	return sliceCmd
 }
 
-func runSlice(format csv.Format, inputPath string, start int, end int, outputPath string) error {
+type SliceOptions struct {
+	Start      int
+	End        int
+	FromByte   int64
+	ToByte     int64 // 指定なしは-1
+	ColumnName string
+	StartWhen  *regexp.Regexp
+	StopWhen   *regexp.Regexp
+}
+
+func (o SliceOptions) hasByteRange() bool {
+	return o.FromByte > 0 || o.ToByte >= 0
+}
+
+func runSlice(format csv.Format, inputPath string, options SliceOptions, outputPath string) error {
 
-	reader, writer, close, err := setupInputOutput(inputPath, outputPath, format)
+	reader, close, err := setupInput(inputPath, format)
 	if err != nil {
 		return err
 	}
 	defer close()
 
-	err = slice(reader, start, end, writer)
+	if options.hasByteRange() {
+		columnNames, err := reader.Read()
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input CSV file")
+		}
+
+		// ヘッダ以外は、指定された範囲のみを読み込む
+		rangeReader, rangeClose, err := openSliceByteRange(format, inputPath, options.FromByte, options.ToByte, len(columnNames))
+		if err != nil {
+			return err
+		}
+		defer rangeClose()
+
+		reader = &headerPrependedReader{header: columnNames, r: rangeReader}
+	}
+
+	writer, outputClose, err := setupOutput(outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer outputClose()
+
+	err = slice(reader, options, writer)
 	if err != nil {
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return outputClose()
 }
 
-func slice(reader csv.CsvReader, start int, end int, writer csv.CsvWriter) error {
+func slice(reader csv.CsvReader, options SliceOptions, writer csv.CsvWriter) error {
 
 	columnNames, err := reader.Read()
 	if err != nil {
 		return errors.Wrap(err, "failed to read the input CSV file")
 	}
 
+	columnIndex := -1
+	if options.ColumnName != "" {
+		columnIndex, err = findColumnIndex(columnNames, options.ColumnName, "input CSV file")
+		if err != nil {
+			return err
+		}
+	}
+
 	err = writer.Write(columnNames)
 	if err != nil {
 		return err
 	}
 
 	currentRowNum := 0
+	started := options.StartWhen == nil
 
-	for currentRowNum <= end {
+	for currentRowNum <= options.End {
 		row, err := reader.Read()
 		if err == io.EOF {
 			break
@@ -100,7 +214,21 @@ func slice(reader csv.CsvReader, start int, end int, writer csv.CsvWriter) error
 
 		currentRowNum++
 
-		if currentRowNum >= start && currentRowNum <= end {
+		if currentRowNum < options.Start {
+			continue
+		}
+
+		if !started {
+			if !options.StartWhen.MatchString(row[columnIndex]) {
+				continue
+			}
+			started = true
+		} else if options.StopWhen != nil && options.StopWhen.MatchString(row[columnIndex]) {
+			// 以降は読まない
+			break
+		}
+
+		if currentRowNum <= options.End {
 			err = writer.Write(row)
 			if err != nil {
 				return err
@@ -110,3 +238,166 @@ func slice(reader csv.CsvReader, start int, end int, writer csv.CsvWriter) error
 
 	return nil
 }
+
+// 先頭にヘッダを付けて返すReader
+type headerPrependedReader struct {
+	header []string
+	r      csv.CsvReader
+}
+
+func (r *headerPrependedReader) Read() ([]string, error) {
+
+	if r.header != nil {
+		header := r.header
+		r.header = nil
+		return header, nil
+	}
+	return r.r.Read()
+}
+
+// 同期の確認で読むレコード数
+const sliceSyncCheckRecords = 10
+
+// ファイルの指定範囲のレコードを読むReaderを返す
+// 範囲の開始位置がレコードの途中の場合は、次のレコードの先頭に合わせる
+func openSliceByteRange(format csv.Format, inputPath string, fromByte int64, toByte int64, columnCount int) (csv.CsvReader, func(), error) {
+
+	if !canSeekInput(format, inputPath) {
+		return nil, nil, fmt.Errorf("from-byte and to-byte can only be used with an uncompressed UTF-8 CSV file separated by LF or CRLF")
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	size := info.Size()
+
+	quote := byte('"')
+	if format.Quote != 0 {
+		quote = byte(format.Quote)
+	}
+
+	// 途中から読むので、ヘッダとしては扱わない
+	rangeFormat := format
+	rangeFormat.NoHeader = false
+	rangeFormat.DedupeHeaders = false
+
+	// ヘッダの次のレコードの位置 (ヘッダ無しの場合はファイルの先頭)
+	var headerEnd int64
+	if !format.NoHeader {
+		headerEnd, err = nextRecordBoundary(file, 0, 1, size, quote)
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+
+	start := headerEnd
+	if fromByte > headerEnd {
+		start, err = syncRecordBoundary(file, rangeFormat, fromByte, size, columnCount)
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+
+	end := size
+	if toByte >= 0 {
+		if toByte <= start {
+			end = start
+		} else {
+			// 開始位置からは引用符の内外が分かるので、指定位置以降で最初のレコードの先頭を探す
+			end, err = nextRecordBoundary(file, start, toByte, size, quote)
+			if err != nil {
+				file.Close()
+				return nil, nil, err
+			}
+		}
+	}
+
+	reader := csv.NewCsvReader(io.NewSectionReader(file, start, end-start), rangeFormat)
+	return reader, func() { file.Close() }, nil
+}
+
+// レコードの先頭(start)から読み進めて、minPosition以降で最初のレコードの先頭の位置を返す (無い場合はファイルサイズ)
+func nextRecordBoundary(file *os.File, start int64, minPosition int64, size int64, quote byte) (int64, error) {
+
+	r := bufio.NewReader(io.NewSectionReader(file, start, size-start))
+	quoted := false
+	position := start
+
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		position++
+
+		switch {
+		case b == quote:
+			// エスケープ("")は2回反転するので、内外の判定はそのままで良い
+			quoted = !quoted
+		case b == '\n' && !quoted && position >= minPosition:
+			return position, nil
+		}
+	}
+}
+
+// 指定位置以降で、レコードの先頭とみなせる位置を返す
+// 改行の直後から読んで、カラム数の合うレコードが続く場合にレコードの先頭とみなす
+// (引用符で囲まれた値の中の改行の直後からでは、通常はカラム数が合わなくなる)
+func syncRecordBoundary(file *os.File, format csv.Format, fromByte int64, size int64, columnCount int) (int64, error) {
+
+	r := bufio.NewReader(io.NewSectionReader(file, fromByte-1, size-fromByte+1))
+	position := fromByte - 1
+
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		position++
+
+		if b != '\n' {
+			continue
+		}
+
+		candidate := position
+		if candidate == size {
+			return size, nil
+		}
+
+		if isRecordBoundary(file, format, candidate, size, columnCount) {
+			return candidate, nil
+		}
+	}
+}
+
+func isRecordBoundary(file *os.File, format csv.Format, position int64, size int64, columnCount int) bool {
+
+	reader := csv.NewCsvReader(io.NewSectionReader(file, position, size-position), format)
+
+	for i := 0; i < sliceSyncCheckRecords; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil || len(row) != columnCount {
+			return false
+		}
+	}
+
+	return true
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"os"
 	"testing"
+
+	"github.com/onozaty/csvt/csv"
 )
 
 func TestSliceCmd(t *testing.T) {
@@ -330,3 +332,291 @@ func TestSliceCmd_inputFileNotFound(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestSliceCmd_byteRange(t *testing.T) {
+
+	// ヘッダ: 0-8, 1行目: 9-20 (引用符内に改行), 2行目: 21-25, 3行目: 26-35 (引用符内に改行), 4行目: 36-40
+	s := joinRows(
+		"id,text",
+		`1,"a`,
+		`2,b"`,
+		"3,c",
+		`4,"d`,
+		`e"`,
+		"5,f",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	tests := []struct {
+		fromByte string
+		toByte   string
+		expect   string
+	}{
+		{"0", "", s},
+		{"9", "", s},
+		{"10", "", joinRows("id,text", "3,c", `4,"d`, `e"`, "5,f")},
+		{"14", "", joinRows("id,text", "3,c", `4,"d`, `e"`, "5,f")},
+		{"21", "", joinRows("id,text", "3,c", `4,"d`, `e"`, "5,f")},
+		{"22", "", joinRows("id,text", `4,"d`, `e"`, "5,f")},
+		{"33", "", joinRows("id,text", "5,f")},
+		{"37", "", joinRows("id,text")},
+		{"", "18", joinRows("id,text", `1,"a`, `2,b"`)},
+		{"10", "26", joinRows("id,text", "3,c")},
+		{"10", "27", joinRows("id,text", "3,c", `4,"d`, `e"`)},
+		{"10", "10", joinRows("id,text")},
+	}
+
+	for _, test := range tests {
+		fo := createTempFile(t, "")
+		defer os.Remove(fo)
+
+		args := []string{"slice", "-i", fi, "-o", fo}
+		if test.fromByte != "" {
+			args = append(args, "--from-byte", test.fromByte)
+		}
+		if test.toByte != "" {
+			args = append(args, "--to-byte", test.toByte)
+		}
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(args)
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+
+		result := readString(t, fo)
+		if result != test.expect {
+			t.Fatal("failed test\n", test.fromByte, test.toByte, result)
+		}
+	}
+}
+
+func TestSliceCmd_byteRangeNoHeader(t *testing.T) {
+
+	s := joinRows(
+		"1,a",
+		"2,b",
+		"3,c",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"--to-byte", "6",
+		"--no-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"1,2",
+		"1,a",
+		"2,b",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSliceCmd_byteRangeCustomSeparator(t *testing.T) {
+
+	fi := createTempFile(t, "col1,col2|1,a|2,b|")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"--sep", "|",
+		"--from-byte", "9",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "from-byte and to-byte can only be used with an uncompressed UTF-8 CSV file separated by LF or CRLF" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSliceCmd_byteRangeCompressed(t *testing.T) {
+
+	fi := createCompressedFile(t, joinRows("col1", "1"), csv.CompressionGzip)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"--from-byte", "10",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "from-byte and to-byte can only be used with an uncompressed UTF-8 CSV file separated by LF or CRLF" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSliceCmd_when(t *testing.T) {
+
+	s := joinRows(
+		"date,value",
+		"2024-02-28,1",
+		"2024-03-01,2",
+		"2024-03-15,3",
+		"2024-03-31,4",
+		"2024-04-01,5",
+		"2024-03-02,6",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"-c", "date",
+		"--start-when", "^2024-03",
+		"--stop-when", "^2024-04",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"date,value",
+		"2024-03-01,2",
+		"2024-03-15,3",
+		"2024-03-31,4",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSliceCmd_stopWhenOnly(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,STOP",
+		"4,d",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col2",
+		"--stop-when", "STOP",
+		"-s", "2",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"col1,col2",
+		"2,b",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSliceCmd_whenColumnNotFound(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("col1", "1"))
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"slice",
+		"-i", fi,
+		"-o", fo,
+		"-c", "col2",
+		"--start-when", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "missing col2 in the input CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSliceCmd_invalidArgs(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"--from-byte", "10", "-s", "2"}, "start and end cannot be specified with from-byte or to-byte"},
+		{[]string{"--from-byte", "abc"}, "invalid from-byte: invalid size abc"},
+		{[]string{"--to-byte", "abc"}, "invalid to-byte: invalid size abc"},
+		{[]string{"--from-byte", "10", "--to-byte", "5"}, "to-byte must be greater than or equal to from-byte"},
+		{[]string{"--start-when", "a"}, "column is required for start-when and stop-when"},
+		{[]string{"-c", "a"}, "column can only be used with start-when or stop-when"},
+		{[]string{"-c", "a", "--start-when", "("}, "regular expression specified in --start-when is invalid: error parsing regexp: missing closing ): `(`"},
+		{[]string{"-c", "a", "--stop-when", "("}, "regular expression specified in --stop-when is invalid: error parsing regexp: missing closing ): `(`"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"slice", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
@@ -23,6 +23,8 @@ func newSortCmd() *cobra.Command {
 			asNumber, _ := cmd.Flags().GetBool("number")
 			useFileRows, _ := cmd.Flags().GetBool("usingfile")
 			outputPath, _ := cmd.Flags().GetString("output")
+			// 出力を圧縮する場合は、一時ファイルも圧縮する
+			compressFileRows := getOutputCompression(outputPath, format) != csv.CompressionNone
 
 			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
 			cmd.SilenceUsage = true
@@ -33,9 +35,10 @@ func newSortCmd() *cobra.Command {
 				targetColumnNames,
 				outputPath,
 				SortOptions{
-					sortDescending: sortDescending,
-					asNumber:       asNumber,
-					useFileRows:    useFileRows,
+					sortDescending:   sortDescending,
+					asNumber:         asNumber,
+					useFileRows:      useFileRows,
+					compressFileRows: compressFileRows,
 				})
 		},
 	}
@@ -54,9 +57,10 @@ func newSortCmd() *cobra.Command {
 }
 
 type SortOptions struct {
-	sortDescending bool
-	asNumber       bool
-	useFileRows    bool
+	sortDescending   bool
+	asNumber         bool
+	useFileRows      bool
+	compressFileRows bool
 }
 
 func runSort(format csv.Format, inputPath string, targetColumnNames []string, outputPath string, options SortOptions) error {
@@ -73,7 +77,12 @@ func runSort(format csv.Format, inputPath string, targetColumnNames []string, ou
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func sort(reader csv.CsvReader, targetColumnNames []string, writer csv.CsvWriter, options SortOptions) error {
@@ -93,7 +102,7 @@ func sort(reader csv.CsvReader, targetColumnNames []string, writer csv.CsvWriter
 	var sortedRows csv.CsvSortedRows
 	var err error
 	if options.useFileRows {
-		sortedRows, err = csv.LoadCsvFileSortedRows(reader, targetColumnNames, compare)
+		sortedRows, err = csv.LoadCsvFileSortedRows(reader, targetColumnNames, compare, options.compressFileRows)
 	} else {
 		sortedRows, err = csv.LoadCsvMemorySortedRows(reader, targetColumnNames, compare)
 	}
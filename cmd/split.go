@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/onozaty/csvt/csv"
+	"github.com/onozaty/csvt/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -28,6 +33,139 @@ func newSplitCmd() *cobra.Command {
 			inputPath, _ := cmd.Flags().GetString("input")
 			maxRows, _ := cmd.Flags().GetInt("rows")
 			outputBasePath, _ := cmd.Flags().GetString("output")
+			sheetColumnName, _ := cmd.Flags().GetString("sheet-by")
+			byColumnName, _ := cmd.Flags().GetString("by")
+			maxOpenFiles, _ := cmd.Flags().GetInt("max-open-files")
+			bytesSize, _ := cmd.Flags().GetString("bytes")
+			parts, _ := cmd.Flags().GetInt("parts")
+			hashColumnName, _ := cmd.Flags().GetString("hash-column")
+
+			if hashColumnName != "" && !cmd.Flags().Changed("parts") {
+				return fmt.Errorf("hash-column can only be used with --parts")
+			}
+
+			if byColumnName != "" || isSplitOutputTemplate(outputBasePath) {
+				if cmd.Flags().Changed("rows") {
+					return fmt.Errorf("rows cannot be specified when splitting by value")
+				}
+				if sheetColumnName != "" {
+					return fmt.Errorf("sheet-by cannot be specified when splitting by value")
+				}
+				if bytesSize != "" {
+					return fmt.Errorf("bytes cannot be specified when splitting by value")
+				}
+				if cmd.Flags().Changed("parts") {
+					return fmt.Errorf("parts cannot be specified when splitting by value")
+				}
+				if byColumnName != "" && isSplitOutputTemplate(outputBasePath) {
+					return fmt.Errorf("by cannot be specified with a template output")
+				}
+				if maxOpenFiles <= 0 {
+					return fmt.Errorf("max-open-files must be greater than or equal to 1")
+				}
+
+				var outputTemplate *template.Template
+				if byColumnName == "" {
+					outputTemplate, err = template.New("output").Option("missingkey=error").Parse(outputBasePath)
+					if err != nil {
+						return errors.Wrap(err, "invalid output template")
+					}
+				}
+
+				// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+				cmd.SilenceUsage = true
+
+				return runSplitValues(
+					format,
+					inputPath,
+					byColumnName,
+					outputTemplate,
+					outputBasePath,
+					maxOpenFiles)
+			}
+
+			if sheetColumnName != "" {
+				if cmd.Flags().Changed("rows") {
+					return fmt.Errorf("rows and sheet-by cannot be specified at the same time")
+				}
+				if bytesSize != "" {
+					return fmt.Errorf("bytes and sheet-by cannot be specified at the same time")
+				}
+				if cmd.Flags().Changed("parts") {
+					return fmt.Errorf("parts and sheet-by cannot be specified at the same time")
+				}
+				if format.OutputFormat != csv.OutputFormatXlsx {
+					return fmt.Errorf("sheet-by can only be used with --output-format xlsx")
+				}
+
+				// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+				cmd.SilenceUsage = true
+
+				return runSplitSheets(
+					format,
+					inputPath,
+					sheetColumnName,
+					outputBasePath)
+			}
+
+			if cmd.Flags().Changed("parts") {
+				if cmd.Flags().Changed("rows") {
+					return fmt.Errorf("rows and parts cannot be specified at the same time")
+				}
+				if bytesSize != "" {
+					return fmt.Errorf("bytes and parts cannot be specified at the same time")
+				}
+				if parts <= 0 {
+					return fmt.Errorf("parts must be greater than or equal to 1")
+				}
+				if hashColumnName != "" && maxOpenFiles <= 0 {
+					return fmt.Errorf("max-open-files must be greater than or equal to 1")
+				}
+
+				// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+				cmd.SilenceUsage = true
+
+				if hashColumnName != "" {
+					return runSplitHash(
+						format,
+						inputPath,
+						parts,
+						hashColumnName,
+						outputBasePath,
+						maxOpenFiles)
+				}
+
+				return runSplitParts(
+					format,
+					inputPath,
+					parts,
+					outputBasePath)
+			}
+
+			if bytesSize != "" {
+				if cmd.Flags().Changed("rows") {
+					return fmt.Errorf("rows and bytes cannot be specified at the same time")
+				}
+				maxBytes, err := util.ParseByteSize(bytesSize)
+				if err != nil {
+					return errors.Wrap(err, "invalid bytes")
+				}
+				if maxBytes <= 0 {
+					return fmt.Errorf("bytes must be greater than 0")
+				}
+				if format.OutputFormat != "" && format.OutputFormat != csv.OutputFormatCsv {
+					return fmt.Errorf("bytes can only be used with csv output")
+				}
+
+				// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+				cmd.SilenceUsage = true
+
+				return runSplitBytes(
+					format,
+					inputPath,
+					maxBytes,
+					outputBasePath)
+			}
 
 			// 最大行数は1以上
 			if maxRows <= 0 {
@@ -48,11 +186,21 @@ func newSplitCmd() *cobra.Command {
 	splitCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
 	splitCmd.MarkFlagRequired("input")
 	splitCmd.Flags().IntP("rows", "r", 0, "Maximum number of rows.")
-	splitCmd.MarkFlagRequired("rows")
+	splitCmd.Flags().StringP("bytes", "b", "", "(optional) Maximum size of each file instead of --rows, such as 100M. K, M and G (1024-based) can be used as units.\n"+
+		"The size is after the encoding conversion and includes the BOM and the header. (The size before compression for compressed output.)")
+	splitCmd.Flags().IntP("parts", "p", 0, "(optional) Number of files to split into instead of --rows. The rows are divided into files with roughly equal row counts in order.")
+	splitCmd.Flags().StringP("hash-column", "", "", "(optional) Name of the column to distribute the rows by the hash of the value. Used with --parts.\n"+
+		"Rows with the same value are output to the same file.")
 	splitCmd.Flags().StringP("output", "o", "",
 		"Output CSV file base path. If you specify \"output.csv\", the file will be output as \"output-1.csv\" \"output-2.csv\" ...\n"+
 			"It is also possible to specify the position of the embedded serial number in \"%d\".")
 	splitCmd.MarkFlagRequired("output")
+	splitCmd.Flags().StringP("sheet-by", "", "", "(optional) Name of the column to split into sheets instead of files.\n"+
+		"One sheet is created for each value of the column in a single xlsx file. Used with --output-format xlsx.")
+	splitCmd.Flags().StringP("by", "", "", "(optional) Name of the column to split by value. One file is created for each value of the column.\n"+
+		"If you specify \"output.csv\", the file will be output as \"output-VALUE.csv\".\n"+
+		"Instead of this, the output can be specified as a template with the column names, such as \"out/{{.region}}.csv\".")
+	splitCmd.Flags().IntP("max-open-files", "", 100, "(optional) Maximum number of output files to keep open at the same time when splitting by value or by --hash-column.")
 
 	return splitCmd
 }
@@ -115,8 +263,12 @@ func makeOutputPath(outputBasePath string, num int) (string, error) {
 		// outputBathPath: dir/output, num: 1
 		//   -> dir/output-1
 
-		ext := filepath.Ext(outputBasePath)
-		outputPath = outputBasePath[:len(outputBasePath)-len(ext)] + "-" + strconv.Itoa(num) + ext
+		// outputBathPath: dir/output.csv.gz, num: 1
+		//   -> dir/output-1.csv.gz
+
+		basePath, compressionExt := csv.SplitCompressionExtension(outputBasePath)
+		ext := filepath.Ext(basePath)
+		outputPath = basePath[:len(basePath)-len(ext)] + "-" + strconv.Itoa(num) + ext + compressionExt
 	}
 
 	dir := filepath.Dir(outputPath)
@@ -160,7 +312,618 @@ func splitOne(format csv.Format, reader csv.CsvReader, maxRows int, columnNames
 		}
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
+}
+
+func runSplitSheets(format csv.Format, inputPath string, sheetColumnName string, outputPath string) error {
+
+	reader, inputClose, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer inputClose()
+
+	output, outputClose, err := createOutputFile(outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer outputClose()
+
+	book := csv.NewXlsxWorkbook(output, format)
+
+	err = splitSheets(reader, sheetColumnName, format.NoOutputHeader, book)
+	if err != nil {
+		return err
+	}
+
+	err = book.Flush()
+	if err != nil {
+		return err
+	}
+
+	return outputClose()
+}
+
+func splitSheets(reader csv.CsvReader, sheetColumnName string, noOutputHeader bool, book *csv.XlsxWorkbook) error {
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	sheetColumnIndex, err := findColumnIndex(columnNames, sheetColumnName, "input CSV file")
+	if err != nil {
+		return err
+	}
+
+	// 値ごとのシート (出現順に作成)
+	sheets := map[string]csv.CsvWriter{}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input CSV file")
+		}
+
+		value := row[sheetColumnIndex]
+		sheet, has := sheets[value]
+		if !has {
+			sheet, err = book.Sheet(value)
+			if err != nil {
+				return err
+			}
+			if !noOutputHeader {
+				if err := sheet.Write(columnNames); err != nil {
+					return err
+				}
+			}
+			sheets[value] = sheet
+		}
+
+		if err := sheet.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runSplitParts(format csv.Format, inputPath string, parts int, outputBasePath string) error {
+
+	// 均等に分けるため、先に行数を数える
+	rowCount, err := countSplitRows(format, inputPath)
+	if err != nil {
+		return err
+	}
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	for num := 1; num <= parts; num++ {
+		outputPath, err := makeOutputPath(outputBasePath, num)
+		if err != nil {
+			return err
+		}
+
+		// 割り切れない分は前のファイルから1行ずつ多く
+		maxRows := rowCount / parts
+		if num <= rowCount%parts {
+			maxRows++
+		}
+
+		err = splitOne(format, reader, maxRows, columnNames, outputPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func countSplitRows(format csv.Format, inputPath string) (int, error) {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return 0, err
+	}
+	defer close()
+
+	if _, err := reader.Read(); err != nil {
+		return 0, errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	count := 0
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to read the input CSV file")
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func runSplitHash(format csv.Format, inputPath string, parts int, hashColumnName string, outputBasePath string, maxOpenFiles int) error {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	hashColumnIndex, err := findColumnIndex(columnNames, hashColumnName, "input CSV file")
+	if err != nil {
+		return err
+	}
+
+	// 値で分ける場合と同じく、同時に開くファイル数は max-open-files まで
+	partitions := newSplitPartitions(format, columnNames, maxOpenFiles)
+	defer partitions.Close()
+
+	// 行が無いファイルも含めて、全て作成しておく
+	outputPaths := []string{}
+	for num := 1; num <= parts; num++ {
+		outputPath, err := makeOutputPath(outputBasePath, num)
+		if err != nil {
+			return err
+		}
+
+		if err := partitions.Create(outputPath); err != nil {
+			return err
+		}
+		outputPaths = append(outputPaths, outputPath)
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input CSV file")
+		}
+
+		hash := fnv.New32a()
+		hash.Write([]byte(row[hashColumnIndex]))
+		outputPath := outputPaths[hash.Sum32()%uint32(parts)]
+
+		if err := partitions.Write(outputPath, row); err != nil {
+			return err
+		}
+	}
+
+	return partitions.Close()
+}
+
+func runSplitBytes(format csv.Format, inputPath string, maxBytes int64, outputBasePath string) error {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	measurer := newRowSizeMeasurer(format)
+
+	// ファイルごとに必ず出力されるBOMとヘッダのサイズ
+	var headerBytes int64
+	if format.WithBom && format.Encoding == nil {
+		// UTF-8のBOM (EF BB BF)
+		headerBytes += 3
+	}
+	if !format.NoOutputHeader {
+		size, err := measurer.Size(columnNames)
+		if err != nil {
+			return err
+		}
+		headerBytes += size
+	}
+
+	num := 0
+	var writer csv.CsvWriter
+	var writerClose func() error
+	var currentBytes int64
+	currentRows := 0
+
+	closeWriter := func() error {
+		err := writer.Flush()
+		if closeErr := writerClose(); err == nil {
+			err = closeErr
+		}
+		writer = nil
+		return err
+	}
+
+	nextWriter := func() error {
+		if writer != nil {
+			if err := closeWriter(); err != nil {
+				return err
+			}
+		}
+
+		num++
+		outputPath, err := makeOutputPath(outputBasePath, num)
+		if err != nil {
+			return err
+		}
+
+		writer, writerClose, err = setupOutput(outputPath, format)
+		if err != nil {
+			return err
+		}
+		currentBytes = headerBytes
+		currentRows = 0
+
+		return writer.Write(columnNames)
+	}
+
+	// ヘッダのみの場合も1ファイルは出力
+	if err := nextWriter(); err != nil {
+		return err
+	}
+	defer func() {
+		if writer != nil {
+			writerClose()
+		}
+	}()
+
+	rowNum := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input CSV file")
+		}
+		rowNum++
+
+		rowBytes, err := measurer.Size(row)
+		if err != nil {
+			return err
+		}
+
+		if headerBytes+rowBytes > maxBytes {
+			return fmt.Errorf("row %d does not fit in %d bytes with the header", rowNum, maxBytes)
+		}
+
+		if currentRows > 0 && currentBytes+rowBytes > maxBytes {
+			// 超える前に次のファイルへ
+			if err := nextWriter(); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		currentBytes += rowBytes
+		currentRows++
+	}
+
+	return closeWriter()
+}
+
+// 1行を出力した際のバイト数を求める (区切り文字、エンコーディングを反映)
+type rowSizeMeasurer struct {
+	buf    *bytes.Buffer
+	writer csv.CsvWriter
+}
+
+func newRowSizeMeasurer(format csv.Format) *rowSizeMeasurer {
+
+	// BOMとヘッダの有無はサイズ計算側で扱う
+	format.WithBom = false
+	format.NoOutputHeader = false
+
+	buf := &bytes.Buffer{}
+	return &rowSizeMeasurer{
+		buf:    buf,
+		writer: csv.NewCsvWriter(buf, format),
+	}
+}
+
+func (m *rowSizeMeasurer) Size(row []string) (int64, error) {
+
+	m.buf.Reset()
+	if err := m.writer.Write(row); err != nil {
+		return 0, err
+	}
+	if err := m.writer.Flush(); err != nil {
+		return 0, err
+	}
+
+	return int64(m.buf.Len()), nil
+}
+
+func isSplitOutputTemplate(outputPath string) bool {
+
+	return strings.Contains(outputPath, "{{")
+}
+
+func runSplitValues(format csv.Format, inputPath string, byColumnName string, outputTemplate *template.Template, outputBasePath string, maxOpenFiles int) error {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	byColumnIndex := -1
+	if byColumnName != "" {
+		byColumnIndex, err = findColumnIndex(columnNames, byColumnName, "input CSV file")
+		if err != nil {
+			return err
+		}
+	}
+
+	partitions := newSplitPartitions(format, columnNames, maxOpenFiles)
+	defer partitions.Close()
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input CSV file")
+		}
+
+		var outputPath string
+		if outputTemplate != nil {
+			outputPath, err = makeTemplateOutputPath(outputTemplate, columnNames, row)
+			if err != nil {
+				return err
+			}
+		} else {
+			outputPath = makeValueOutputPath(outputBasePath, row[byColumnIndex])
+		}
+
+		if err := partitions.Write(outputPath, row); err != nil {
+			return err
+		}
+	}
+
+	return partitions.Close()
+}
+
+func makeValueOutputPath(outputBasePath string, value string) string {
+
+	// outputBasePath: dir/output.csv, value: tokyo
+	//   -> dir/output-tokyo.csv
+	basePath, compressionExt := csv.SplitCompressionExtension(outputBasePath)
+	ext := filepath.Ext(basePath)
+	return basePath[:len(basePath)-len(ext)] + "-" + sanitizeFileName(value) + ext + compressionExt
+}
+
+func makeTemplateOutputPath(outputTemplate *template.Template, columnNames []string, row []string) (string, error) {
+
+	// outputTemplate: out/{{.region}}.csv, region: tokyo
+	//   -> out/tokyo.csv
+
+	// 値はファイル名として使えるように置き換え
+	values := map[string]string{}
+	for i, columnName := range columnNames {
+		if _, has := values[columnName]; has {
+			// 同じ名前のカラムが複数ある場合は最初のもの
+			continue
+		}
+		value := ""
+		if i < len(row) {
+			value = row[i]
+		}
+		values[columnName] = sanitizeFileName(value)
+	}
+
+	b := strings.Builder{}
+	if err := outputTemplate.Execute(&b, values); err != nil {
+		return "", errors.Wrap(err, "failed to make the output path")
+	}
+
+	return b.String(), nil
+}
+
+var unsafeFileNameRunes = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]`)
+
+// 値をファイル名の一部として安全に使えるように (区切り文字や使えない文字は"_"に)
+func sanitizeFileName(value string) string {
+
+	name := unsafeFileNameRunes.ReplaceAllString(value, "_")
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// 値ごとの出力ファイル
+// 同時に開くファイル数に上限を設け、超えた場合は最も使われていないものを閉じる (再度書き込む際は追記)
+type splitPartitions struct {
+	format       csv.Format
+	columnNames  []string
+	maxOpenFiles int
+	partitions   map[string]*splitPartition
+	openCount    int
+	useCount     int
+}
+
+type splitPartition struct {
+	writer   csv.CsvWriter
+	close    func() error
+	lastUsed int
+}
+
+func newSplitPartitions(format csv.Format, columnNames []string, maxOpenFiles int) *splitPartitions {
+
+	return &splitPartitions{
+		format:       format,
+		columnNames:  columnNames,
+		maxOpenFiles: maxOpenFiles,
+		partitions:   map[string]*splitPartition{},
+	}
+}
+
+func (p *splitPartitions) Write(outputPath string, row []string) error {
+
+	partition, err := p.use(outputPath)
+	if err != nil {
+		return err
+	}
+
+	return partition.writer.Write(row)
+}
+
+// 行が無くてもファイルを作成しておく
+func (p *splitPartitions) Create(outputPath string) error {
+
+	_, err := p.use(outputPath)
+	return err
+}
+
+func (p *splitPartitions) use(outputPath string) (*splitPartition, error) {
+
+	partition, has := p.partitions[outputPath]
+	if !has {
+		partition = &splitPartition{}
+		p.partitions[outputPath] = partition
+	}
+
+	if partition.writer == nil {
+		if err := p.open(outputPath, partition, has); err != nil {
+			return nil, err
+		}
+	}
+
+	p.useCount++
+	partition.lastUsed = p.useCount
+
+	return partition, nil
+}
+
+func (p *splitPartitions) open(outputPath string, partition *splitPartition, reopen bool) error {
+
+	if p.openCount >= p.maxOpenFiles {
+		if !isAppendableOutputFormat(p.format.OutputFormat) {
+			return fmt.Errorf("number of output files exceeds max-open-files (%d), and %s output cannot be reopened", p.maxOpenFiles, p.format.OutputFormat)
+		}
+		if err := p.closeLeastUsed(); err != nil {
+			return err
+		}
+	}
+
+	format := p.format
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if reopen {
+		// 続きから書き込む (BOMやヘッダは出力済み)
+		// JSONや固定長はヘッダからキーやカラムを決めるので、ヘッダは渡した上で出力しないように
+		flag = os.O_WRONLY | os.O_APPEND
+		format.WithBom = false
+		format.NoOutputHeader = true
+	} else {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return err
+		}
+	}
+
+	output, close, err := openOutputFile(outputPath, format, flag)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewCsvWriter(output, format)
+	if err := writer.Write(p.columnNames); err != nil {
+		close()
+		return err
+	}
+
+	partition.writer = writer
+	partition.close = close
+	p.openCount++
+
+	return nil
+}
+
+func (p *splitPartitions) closeLeastUsed() error {
+
+	var leastUsed *splitPartition
+	for _, partition := range p.partitions {
+		if partition.writer != nil && (leastUsed == nil || partition.lastUsed < leastUsed.lastUsed) {
+			leastUsed = partition
+		}
+	}
+
+	return p.closePartition(leastUsed)
+}
+
+func (p *splitPartitions) closePartition(partition *splitPartition) error {
+
+	err := partition.writer.Flush()
+	if closeErr := partition.close(); err == nil {
+		err = closeErr
+	}
+	partition.writer = nil
+	partition.close = nil
+	p.openCount--
+
+	return err
+}
+
+func (p *splitPartitions) Close() error {
+
+	var firstErr error
+	for _, partition := range p.partitions {
+		if partition.writer != nil {
+			if err := p.closePartition(partition); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// 閉じた後に追記しても正しい形式になるか
+func isAppendableOutputFormat(outputFormat string) bool {
+
+	switch outputFormat {
+	case "", csv.OutputFormatCsv, csv.OutputFormatNdjson, csv.OutputFormatFixed:
+		return true
+	}
+	return false
 }
 
 type splitReader struct {
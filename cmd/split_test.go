@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/onozaty/csvt/csv"
+	"golang.org/x/text/encoding/japanese"
 )
 
 func TestSplitCmd(t *testing.T) {
@@ -570,3 +575,1180 @@ func TestSplitCmd_outputBase_nonExtension(t *testing.T) {
 		t.Fatal("failed test\n", result)
 	}
 }
+
+func TestSplitCmd_noHeader(t *testing.T) {
+
+	s := joinRows(
+		"1,a",
+		"2,b",
+		"3,c",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"-r", "2",
+		"--no-header",
+		"--no-output-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	// 各ファイルともヘッダは出力されない
+	expect := map[string][]byte{
+		"output-1.csv": []byte(joinRows(
+			"1,a",
+			"2,b")),
+		"output-2.csv": []byte(joinRows(
+			"3,c")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_sheetBy(t *testing.T) {
+
+	s := joinRows(
+		"ID,Group",
+		"1,A",
+		"2,B",
+		"3,A",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", fo,
+		"--sheet-by", "Group",
+		"--output-format", "xlsx",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// シートごとにCSVに変換して確認
+	expects := map[string]string{
+		"A": joinRows(
+			"ID,Group",
+			"1,A",
+			"3,A",
+		),
+		"B": joinRows(
+			"ID,Group",
+			"2,B",
+		),
+	}
+
+	for sheet, expect := range expects {
+
+		fc := createTempFile(t, "")
+		defer os.Remove(fc)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"transform",
+			"-i", fo,
+			"-o", fc,
+			"--input-format", "xlsx",
+			"--sheet", sheet,
+		})
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+
+		result := readString(t, fc)
+		if result != expect {
+			t.Fatal("failed test\n", sheet, result)
+		}
+	}
+}
+
+func TestSplitCmd_sheetByNoOutputHeader(t *testing.T) {
+
+	s := joinRows(
+		"ID,Group",
+		"1,A",
+		"2,B",
+		"3,A",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", fo,
+		"--sheet-by", "Group",
+		"--output-format", "xlsx",
+		"--no-output-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 各シートにヘッダが無いこと
+	expects := map[string]string{
+		"A": joinRows(
+			"1,A",
+			"3,A",
+		),
+		"B": joinRows(
+			"2,B",
+		),
+	}
+
+	for sheet, expect := range expects {
+
+		fc := createTempFile(t, "")
+		defer os.Remove(fc)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"transform",
+			"-i", fo,
+			"-o", fc,
+			"--input-format", "xlsx",
+			"--sheet", sheet,
+		})
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+
+		result := readString(t, fc)
+		if result != expect {
+			t.Fatal("failed test\n", sheet, result)
+		}
+	}
+}
+
+func TestSplitCmd_sheetByWithoutXlsx(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", "input.csv",
+		"-o", "output.xlsx",
+		"--sheet-by", "Group",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "sheet-by can only be used with --output-format xlsx" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_sheetByWithRows(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", "input.csv",
+		"-o", "output.xlsx",
+		"-r", "2",
+		"--sheet-by", "Group",
+		"--output-format", "xlsx",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "rows and sheet-by cannot be specified at the same time" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_compressed(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,c",
+	)
+
+	fi := createCompressedFile(t, s, csv.CompressionXz)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv.gz",
+		"-r", "2",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := map[string]string{}
+	for name := range readDir(t, d) {
+		result[name] = readDecompressedString(t, filepath.Join(d, name))
+	}
+
+	expect := map[string]string{
+		"output-1.csv.gz": joinRows(
+			"col1,col2",
+			"1,a",
+			"2,b"),
+		"output-2.csv.gz": joinRows(
+			"col1,col2",
+			"3,c"),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_by(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+		"osaka,2",
+		"tokyo,3",
+		"a/b,4",
+		",5",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--by", "region",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	expect := map[string][]byte{
+		"output-tokyo.csv": []byte(joinRows(
+			"region,value",
+			"tokyo,1",
+			"tokyo,3")),
+		"output-osaka.csv": []byte(joinRows(
+			"region,value",
+			"osaka,2")),
+		"output-a_b.csv": []byte(joinRows(
+			"region,value",
+			"a/b,4")),
+		"output-_.csv": []byte(joinRows(
+			"region,value",
+			",5")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_template(t *testing.T) {
+
+	s := joinRows(
+		"region,city,value",
+		"kanto,tokyo,1",
+		"kansai,osaka,2",
+		"kanto,yokohama,3",
+		"kanto,tokyo,4",
+		"..,x,5",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/{{.region}}/{{.city}}.csv",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, filepath.Join(d, "kanto", "tokyo.csv"))
+	if result != joinRows("region,city,value", "kanto,tokyo,1", "kanto,tokyo,4") {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = readString(t, filepath.Join(d, "kanto", "yokohama.csv"))
+	if result != joinRows("region,city,value", "kanto,yokohama,3") {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = readString(t, filepath.Join(d, "kansai", "osaka.csv"))
+	if result != joinRows("region,city,value", "kansai,osaka,2") {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = readString(t, filepath.Join(d, "_", "x.csv"))
+	if result != joinRows("region,city,value", "..,x,5") {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_templateMissingColumn(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/{{.city}}.csv",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to make the output path: ") || !strings.HasSuffix(err.Error(), `map has no entry for key "city"`) {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_maxOpenFiles(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+		"osaka,2",
+		"nagoya,3",
+		"tokyo,4",
+		"osaka,5",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--by", "region",
+		"--max-open-files", "2",
+		"--bom",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	bom := "\ufeff"
+	expect := map[string][]byte{
+		"output-tokyo.csv": []byte(bom + joinRows(
+			"region,value",
+			"tokyo,1",
+			"tokyo,4")),
+		"output-osaka.csv": []byte(bom + joinRows(
+			"region,value",
+			"osaka,2",
+			"osaka,5")),
+		"output-nagoya.csv": []byte(bom + joinRows(
+			"region,value",
+			"nagoya,3")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_maxOpenFilesNoOutputHeader(t *testing.T) {
+
+	s := joinRows(
+		"k,v",
+		"a,1",
+		"b,2",
+		"a,3",
+		"b,4",
+		"a,5",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--by", "k",
+		"--max-open-files", "1",
+		"--no-output-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	// 開き直した後の行も欠けない
+	expect := map[string][]byte{
+		"output-a.csv": []byte(joinRows(
+			"a,1",
+			"a,3",
+			"a,5")),
+		"output-b.csv": []byte(joinRows(
+			"b,2",
+			"b,4")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_maxOpenFilesNdjson(t *testing.T) {
+
+	s := joinRows(
+		"k,v",
+		"a,1",
+		"b,2",
+		"a,3",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.ndjson",
+		"--by", "k",
+		"--max-open-files", "1",
+		"--output-format", "ndjson",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	// 開き直した後もキーはヘッダのもの
+	expect := map[string][]byte{
+		"output-a.ndjson": []byte("{\"k\":\"a\",\"v\":\"1\"}\n{\"k\":\"a\",\"v\":\"3\"}\n"),
+		"output-b.ndjson": []byte("{\"k\":\"b\",\"v\":\"2\"}\n"),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_maxOpenFilesCompressed(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+		"osaka,2",
+		"tokyo,3",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv.gz",
+		"--by", "region",
+		"--max-open-files", "1",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDecompressedString(t, filepath.Join(d, "output-tokyo.csv.gz"))
+	if result != joinRows("region,value", "tokyo,1", "tokyo,3") {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_maxOpenFilesNotAppendable(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+		"osaka,2",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.json",
+		"--by", "region",
+		"--max-open-files", "1",
+		"--output-format", "json",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "number of output files exceeds max-open-files (1), and json output cannot be reopened" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_byInvalidArgs(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"--by", "a", "-r", "1"}, "rows cannot be specified when splitting by value"},
+		{[]string{"--by", "a", "--sheet-by", "a"}, "sheet-by cannot be specified when splitting by value"},
+		{[]string{"--by", "a", "--max-open-files", "0"}, "max-open-files must be greater than or equal to 1"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"split", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"split", "-i", "input.csv", "-o", "{{.a}}.csv", "--by", "a"})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "by cannot be specified with a template output" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_byColumnNotFound(t *testing.T) {
+
+	s := joinRows(
+		"region,value",
+		"tokyo,1",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--by", "city",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "missing city in the input CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_bytes(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,c",
+		"4,d",
+		"5,e",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	// ヘッダ(11バイト) + 2行(5バイト x 2)
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--bytes", "21",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	expect := map[string][]byte{
+		"output-1.csv": []byte(joinRows(
+			"col1,col2",
+			"1,a",
+			"2,b")),
+		"output-2.csv": []byte(joinRows(
+			"col1,col2",
+			"3,c",
+			"4,d")),
+		"output-3.csv": []byte(joinRows(
+			"col1,col2",
+			"5,e")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_bytesEncoding(t *testing.T) {
+
+	s := joinRows(
+		"col1",
+		"あい",
+		"うえ",
+		"お",
+	)
+
+	sjis, _ := japanese.ShiftJIS.NewEncoder().String(s)
+	fi := createTempFile(t, sjis)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	// Shift_JISでは ヘッダ(6バイト) + "あい"(6バイト) + "うえ"(6バイト)
+	// (UTF-8では1ファイルに1行しか入らない)
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--bytes", "18",
+		"--encoding", "sjis",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+	if len(result) != 2 || len(result["output-1.csv"]) != 18 {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_bytesBom(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,c",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	// BOM(3バイト)の分、1ファイルに1行しか入らない
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--bytes", "21",
+		"--bom",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	bom := "\ufeff"
+	expect := map[string][]byte{
+		"output-1.csv": []byte(bom + joinRows(
+			"col1,col2",
+			"1,a")),
+		"output-2.csv": []byte(bom + joinRows(
+			"col1,col2",
+			"2,b")),
+		"output-3.csv": []byte(bom + joinRows(
+			"col1,col2",
+			"3,c")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_bytesUnit(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"-b", "1K",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	expect := map[string][]byte{
+		"output-1.csv": []byte(s),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_bytesRowTooLarge(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,bbbbbbbbbb",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--bytes", "20",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "row 2 does not fit in 20 bytes with the header" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_bytesInvalidArgs(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"--bytes", "10", "-r", "1"}, "rows and bytes cannot be specified at the same time"},
+		{[]string{"--bytes", "abc"}, "invalid bytes: invalid size abc"},
+		{[]string{"--bytes", "0"}, "bytes must be greater than 0"},
+		{[]string{"--bytes", "10", "--output-format", "json"}, "bytes can only be used with csv output"},
+		{[]string{"--bytes", "10", "--by", "a"}, "bytes cannot be specified when splitting by value"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"split", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestSplitCmd_parts(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,c",
+		"4,d",
+		"5,e",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--parts", "3",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	expect := map[string][]byte{
+		"output-1.csv": []byte(joinRows(
+			"col1,col2",
+			"1,a",
+			"2,b")),
+		"output-2.csv": []byte(joinRows(
+			"col1,col2",
+			"3,c",
+			"4,d")),
+		"output-3.csv": []byte(joinRows(
+			"col1,col2",
+			"5,e")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_partsOverRows(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"-p", "3",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+
+	expect := map[string][]byte{
+		"output-1.csv": []byte(joinRows(
+			"col1,col2",
+			"1,a")),
+		"output-2.csv": []byte(joinRows(
+			"col1,col2")),
+		"output-3.csv": []byte(joinRows(
+			"col1,col2")),
+	}
+
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_hashColumn(t *testing.T) {
+
+	s := joinRows(
+		"user_id,value",
+		"u1,1",
+		"u2,2",
+		"u3,3",
+		"u1,4",
+		"u2,5",
+		"u4,6",
+		"u1,7",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--parts", "2",
+		"--hash-column", "user_id",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readDir(t, d)
+	if len(result) != 2 {
+		t.Fatal("failed test\n", result)
+	}
+
+	// 同じ値の行は同じファイルに、元の順序で出力される
+	userFiles := map[string]string{}
+	total := 0
+	for name, content := range result {
+		rows := strings.Split(strings.TrimSuffix(string(content), "\r\n"), "\r\n")
+		if rows[0] != "user_id,value" {
+			t.Fatal("failed test\n", name, rows)
+		}
+
+		for _, row := range rows[1:] {
+			userId := strings.Split(row, ",")[0]
+			if file, has := userFiles[userId]; has && file != name {
+				t.Fatal("failed test\n", userId, file, name)
+			}
+			userFiles[userId] = name
+			total++
+		}
+	}
+
+	if total != 7 {
+		t.Fatal("failed test\n", total)
+	}
+
+	u1 := string(result[userFiles["u1"]])
+	if !strings.Contains(u1, "u1,1\r\n") || strings.Index(u1, "u1,1") > strings.Index(u1, "u1,4") || strings.Index(u1, "u1,4") > strings.Index(u1, "u1,7") {
+		t.Fatal("failed test\n", u1)
+	}
+}
+
+func TestSplitCmd_hashColumnMaxOpenFiles(t *testing.T) {
+
+	s := joinRows(
+		"user_id,value",
+		"u1,1",
+		"u2,2",
+		"u3,3",
+		"u1,4",
+		"u2,5",
+		"u4,6",
+		"u1,7",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	split := func(maxOpenFiles string) map[string][]byte {
+
+		d := createTempDir(t)
+		defer os.RemoveAll(d)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"split",
+			"-i", fi,
+			"-o", d + "/output.csv",
+			"--parts", "3",
+			"--hash-column", "user_id",
+			"--max-open-files", maxOpenFiles,
+		})
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+
+		return readDir(t, d)
+	}
+
+	// 同時に開くファイル数を制限しても(閉じたファイルには追記)、結果は同じ
+	expect := split("100")
+	result := split("1")
+	if len(result) != 3 || !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSplitCmd_hashColumnMaxOpenFilesNotAppendable(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("user_id", "u1", "u2"))
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.json",
+		"--parts", "2",
+		"--hash-column", "user_id",
+		"--max-open-files", "1",
+		"--output-format", "json",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "number of output files exceeds max-open-files (1), and json output cannot be reopened" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSplitCmd_partsInvalidArgs(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"--parts", "2", "-r", "1"}, "rows and parts cannot be specified at the same time"},
+		{[]string{"--parts", "2", "--bytes", "10"}, "bytes and parts cannot be specified at the same time"},
+		{[]string{"--parts", "0"}, "parts must be greater than or equal to 1"},
+		{[]string{"--hash-column", "a"}, "hash-column can only be used with --parts"},
+		{[]string{"--parts", "2", "--hash-column", "a", "--max-open-files", "0"}, "max-open-files must be greater than or equal to 1"},
+		{[]string{"--parts", "2", "--by", "a"}, "parts cannot be specified when splitting by value"},
+		{[]string{"--parts", "2", "--sheet-by", "a"}, "parts and sheet-by cannot be specified at the same time"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"split", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestSplitCmd_hashColumnNotFound(t *testing.T) {
+
+	s := joinRows(
+		"user_id,value",
+		"u1,1",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"split",
+		"-i", fi,
+		"-o", d + "/output.csv",
+		"--parts", "2",
+		"--hash-column", "id",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "missing id in the input CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
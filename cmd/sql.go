@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newSqlCmd() *cobra.Command {
+
+	sqlCmd := &cobra.Command{
+		Use:   "sql",
+		Short: "Generate SQL",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			outputPath, _ := cmd.Flags().GetString("output")
+			table, _ := cmd.Flags().GetString("table")
+			dialect, _ := cmd.Flags().GetString("dialect")
+			mode, _ := cmd.Flags().GetString("mode")
+			batchSize, _ := cmd.Flags().GetInt("batch-size")
+			schemaPath, _ := cmd.Flags().GetString("schema")
+			inferRows, _ := cmd.Flags().GetInt("rows")
+			noCreateTable, _ := cmd.Flags().GetBool("no-create-table")
+
+			switch dialect {
+			case csv.SqlDialectPostgresql, csv.SqlDialectMysql, csv.SqlDialectSqlite:
+			default:
+				return fmt.Errorf("dialect should be specified with postgresql, mysql or sqlite")
+			}
+
+			switch mode {
+			case csv.SqlModeInsert:
+			case csv.SqlModeCopy:
+				if dialect != csv.SqlDialectPostgresql {
+					return fmt.Errorf("mode copy can only be used with --dialect postgresql")
+				}
+			default:
+				return fmt.Errorf("mode should be specified with insert or copy")
+			}
+
+			if batchSize < 1 {
+				return fmt.Errorf("batch-size must be greater than 0")
+			}
+			if inferRows < 0 {
+				return fmt.Errorf("rows must be greater than or equal to 0")
+			}
+
+			var schema *csv.Schema
+			if schemaPath != "" {
+				schema, err = csv.LoadSchema(schemaPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runSql(
+				format,
+				inputPath,
+				csv.SqlOptions{
+					Dialect:       dialect,
+					Mode:          mode,
+					Table:         table,
+					BatchSize:     batchSize,
+					NoCreateTable: noCreateTable,
+					Schema:        schema,
+				},
+				inferRows,
+				outputPath,
+				cmd.OutOrStdout())
+		},
+	}
+
+	sqlCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	sqlCmd.MarkFlagRequired("input")
+	sqlCmd.Flags().StringP("table", "t", "", "Table name.")
+	sqlCmd.MarkFlagRequired("table")
+	sqlCmd.Flags().StringP("output", "o", "", "(optional) Output SQL file path. If not specified, it will be output to the standard output.")
+	sqlCmd.Flags().StringP("dialect", "d", csv.SqlDialectPostgresql, "(optional) SQL dialect. postgresql, mysql or sqlite can be specified.")
+	sqlCmd.Flags().StringP("mode", "m", csv.SqlModeInsert, "(optional) insert: INSERT statements, copy: COPY statement (postgresql only).")
+	sqlCmd.Flags().IntP("batch-size", "b", 100, "(optional) Number of rows per INSERT statement.")
+	sqlCmd.Flags().StringP("schema", "s", "", "(optional) Schema file path to declare the column types. JSON or YAML(.yaml, .yml) can be used.\nIf not specified, the types are inferred from the CSV file.")
+	sqlCmd.Flags().IntP("rows", "r", 0, "(optional) Number of rows to scan for inferring the types. If not specified, all rows are scanned.")
+	sqlCmd.Flags().BoolP("no-create-table", "", false, "(optional) Do not output CREATE TABLE.")
+
+	return sqlCmd
+}
+
+func runSql(format csv.Format, inputPath string, options csv.SqlOptions, inferRows int, outputPath string, stdout io.Writer) error {
+
+	if options.Schema == nil {
+		// 型を推定するために一度読み込む
+		schema, err := inferSqlSchema(format, inputPath, inferRows)
+		if err != nil {
+			return err
+		}
+		options.Schema = schema
+	}
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	out := stdout
+	outputClose := func() error { return nil }
+	if outputPath != "" {
+		output, close, err := createOutputFile(outputPath, format)
+		if err != nil {
+			return err
+		}
+		defer close()
+		out = output
+		outputClose = close
+	}
+
+	writer, err := csv.NewSqlWriter(out, options)
+	if err != nil {
+		return err
+	}
+
+	if err := copy(reader, writer); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	return outputClose()
+}
+
+func inferSqlSchema(format csv.Format, inputPath string, inferRows int) (*csv.Schema, error) {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	schema, err := csv.InferSchema(reader, inferRows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the CSV file")
+	}
+
+	if inferRows > 0 {
+		// 一部の行からの推定では、NULLが無いとは言い切れない
+		for _, column := range schema.Columns {
+			column.NotNull = false
+		}
+	}
+
+	return schema, nil
+}
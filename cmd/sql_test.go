@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSqlCmd(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Price",
+		"1,Yamada,100",
+		"2,,1.5",
+		"3,Sato,",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", f,
+		"-t", "users",
+		"-b", "2",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := `CREATE TABLE "users" (
+  "ID" BIGINT NOT NULL,
+  "Name" TEXT,
+  "Price" NUMERIC
+);
+INSERT INTO "users" ("ID", "Name", "Price") VALUES
+(1, 'Yamada', 100),
+(2, NULL, 1.5);
+INSERT INTO "users" ("ID", "Name", "Price") VALUES
+(3, 'Sato', NULL);
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlCmd_inferDatetimeAndLeadingZero(t *testing.T) {
+
+	s := joinRows(
+		"id,ts,zip",
+		"1,2024-01-02 10:30:00,00123",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", f,
+		"-t", "t",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	// 時刻を含む日付はTIMESTAMP、先頭が0の数値は文字列
+	expect := `CREATE TABLE "t" (
+  "id" BIGINT NOT NULL,
+  "ts" TIMESTAMP NOT NULL,
+  "zip" TEXT NOT NULL
+);
+INSERT INTO "t" ("id", "ts", "zip") VALUES
+(1, '2024-01-02 10:30:00', '00123');
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlCmd_rows(t *testing.T) {
+
+	s := joinRows(
+		"ID,Code",
+		"1,10",
+		"2,A1",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	o := filepath.Join(d, "output.sql")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", f,
+		"-t", "codes",
+		"-d", "sqlite",
+		"-r", "1",
+		"--no-create-table",
+		"-o", o,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "value A1 of the column Code cannot be written as integer" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSqlCmd_schema(t *testing.T) {
+
+	s := joinRows(
+		"ID,Code",
+		"1,007",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	schemaPath := filepath.Join(d, "schema.json")
+	if err := os.WriteFile(schemaPath, []byte(`{"columns": [{"name": "ID", "type": "integer"}]}`), 0644); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	o := filepath.Join(d, "output.sql")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", f,
+		"-t", "codes",
+		"-d", "mysql",
+		"-s", schemaPath,
+		"-o", o,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, o)
+
+	expect := "CREATE TABLE `codes` (\n" +
+		"  `ID` BIGINT,\n" +
+		"  `Code` TEXT\n" +
+		");\n" +
+		"INSERT INTO `codes` (`ID`, `Code`) VALUES\n" +
+		"(1, '007');\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlCmd_copy(t *testing.T) {
+
+	s := joinRows(
+		"ID,Active",
+		"1,true",
+		"2,",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", f,
+		"-t", "flags",
+		"-m", "copy",
+		"--no-create-table",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := "COPY \"flags\" (\"ID\", \"Active\") FROM stdin;\n" +
+		"1\tt\n" +
+		"2\t\\N\n" +
+		"\\.\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlCmd_invalidFlags(t *testing.T) {
+
+	s := joinRows(
+		"ID",
+		"1",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"-d", "oracle"}, "dialect should be specified with postgresql, mysql or sqlite"},
+		{[]string{"-m", "upsert"}, "mode should be specified with insert or copy"},
+		{[]string{"-d", "mysql", "-m", "copy"}, "mode copy can only be used with --dialect postgresql"},
+		{[]string{"-b", "0"}, "batch-size must be greater than 0"},
+		{[]string{"-r", "-1"}, "rows must be greater than or equal to 0"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"sql", "-i", f, "-t", "t"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestSqlCmd_fileNotFound(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"sql",
+		"-i", "not_found.csv",
+		"-t", "users",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "open not_found.csv: no such file or directory" {
+		t.Fatal("failed test\n", err)
+	}
+}
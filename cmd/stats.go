@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/onozaty/csvt/stats"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newStatsCmd() *cobra.Command {
+
+	statsCmd := &cobra.Command{
+		Use:     "stats",
+		Aliases: []string{"describe"},
+		Short:   "Show statistics for each column",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			targetColumnNames, _ := cmd.Flags().GetStringArray("column")
+			outputPath, _ := cmd.Flags().GetString("output")
+			approx, _ := cmd.Flags().GetBool("approx")
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runStats(
+				format,
+				inputPath,
+				targetColumnNames,
+				outputPath,
+				approx)
+		},
+	}
+
+	statsCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	statsCmd.MarkFlagRequired("input")
+	statsCmd.Flags().StringArrayP("column", "c", []string{}, "(optional) Name of the column to be aggregated. If not specified, all columns are targeted.")
+	statsCmd.Flags().StringP("output", "o", "", "Output CSV file path.")
+	statsCmd.MarkFlagRequired("output")
+	statsCmd.Flags().BoolP("approx", "", false, "(optional) Use approximate algorithms for distinct count, percentiles and top values.\n"+
+		"Memory usage does not depend on the number of rows, so it is suitable for large files.")
+
+	return statsCmd
+}
+
+var statsPercentiles = []float64{0.25, 0.5, 0.75}
+
+const statsTopCount = 5
+
+func runStats(format csv.Format, inputPath string, targetColumnNames []string, outputPath string, approx bool) error {
+
+	reader, writer, close, err := setupInputOutput(inputPath, outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	err = aggregateStats(reader, targetColumnNames, writer, approx)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
+}
+
+func aggregateStats(reader csv.CsvReader, targetColumnNames []string, writer csv.CsvWriter, approx bool) error {
+
+	// ヘッダ
+	columnNames, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the CSV file")
+	}
+
+	targetColumnIndexes, err := getTargetColumnsIndexes(columnNames, targetColumnNames)
+	if err != nil {
+		return err
+	}
+
+	columnStatsList := []*columnStats{}
+	for range targetColumnIndexes {
+		columnStatsList = append(columnStatsList, newColumnStats(approx))
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the CSV file")
+		}
+
+		for i, targetColumnIndex := range targetColumnIndexes {
+			columnStatsList[i].add(row[targetColumnIndex])
+		}
+	}
+
+	err = writer.Write([]string{
+		"Column", "Count", "Distinct", "Min", "Max", "MinLength", "MaxLength",
+		"NumericMin", "NumericMax", "Mean", "Stddev", "P25", "Median", "P75", "Top5"})
+	if err != nil {
+		return err
+	}
+
+	for i, targetColumnIndex := range targetColumnIndexes {
+		err = writer.Write(columnStatsList[i].record(columnNames[targetColumnIndex]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type columnStats struct {
+	count      int
+	min        string
+	max        string
+	minLength  int
+	maxLength  int
+	numeric    bool
+	numericMin float64
+	numericMax float64
+	mean       float64
+	m2         float64
+	counter    stats.Counter
+	quantiles  stats.QuantileEstimator
+}
+
+func newColumnStats(approx bool) *columnStats {
+
+	if approx {
+		return &columnStats{
+			numeric:   true,
+			counter:   stats.NewApproxCounter(),
+			quantiles: stats.NewTDigest(100),
+		}
+	}
+
+	return &columnStats{
+		numeric:   true,
+		counter:   stats.NewExactCounter(),
+		quantiles: stats.NewExactQuantiles(),
+	}
+}
+
+func (s *columnStats) add(value string) {
+
+	// 空の値は対象外
+	if value == "" {
+		return
+	}
+
+	s.count++
+	s.counter.Add(value)
+
+	length := utf8.RuneCountInString(value)
+	if s.count == 1 {
+		s.min = value
+		s.max = value
+		s.minLength = length
+		s.maxLength = length
+	} else {
+		if value < s.min {
+			s.min = value
+		}
+		if value > s.max {
+			s.max = value
+		}
+		if length < s.minLength {
+			s.minLength = length
+		}
+		if length > s.maxLength {
+			s.maxLength = length
+		}
+	}
+
+	if !s.numeric {
+		return
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		// 数値以外が含まれていたら数値としての集計は行わない
+		s.numeric = false
+		return
+	}
+
+	if s.count == 1 {
+		s.numericMin = number
+		s.numericMax = number
+	} else {
+		s.numericMin = math.Min(s.numericMin, number)
+		s.numericMax = math.Max(s.numericMax, number)
+	}
+
+	// Welfordのアルゴリズムで平均と分散を逐次計算
+	delta := number - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (number - s.mean)
+
+	s.quantiles.Add(number)
+}
+
+func (s *columnStats) record(columnName string) []string {
+
+	record := []string{
+		columnName,
+		strconv.Itoa(s.count),
+		strconv.Itoa(s.counter.Distinct()),
+		s.min,
+		s.max,
+	}
+
+	if s.count == 0 {
+		record = append(record, "", "")
+	} else {
+		record = append(record, strconv.Itoa(s.minLength), strconv.Itoa(s.maxLength))
+	}
+
+	if s.numeric && s.count != 0 {
+		stddev := ""
+		if s.count > 1 {
+			// 標本標準偏差
+			stddev = formatFloat(math.Sqrt(s.m2 / float64(s.count-1)))
+		}
+
+		record = append(record,
+			formatFloat(s.numericMin),
+			formatFloat(s.numericMax),
+			formatFloat(s.mean),
+			stddev)
+
+		for _, p := range statsPercentiles {
+			record = append(record, formatFloat(s.quantiles.Quantile(p)))
+		}
+	} else {
+		record = append(record, "", "", "", "")
+		for range statsPercentiles {
+			record = append(record, "")
+		}
+	}
+
+	tops := []string{}
+	for _, item := range s.counter.Top(statsTopCount) {
+		tops = append(tops, fmt.Sprintf("%s (%d)", item.Value, item.Count))
+	}
+	record = append(record, strings.Join(tops, ", "))
+
+	return record
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
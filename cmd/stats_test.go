@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStatsCmd(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Price",
+		"1,Yamada,100",
+		"2,Sato,1.5",
+		"3,Sato,",
+		"4,Tanaka,20",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Column,Count,Distinct,Min,Max,MinLength,MaxLength,NumericMin,NumericMax,Mean,Stddev,P25,Median,P75,Top5",
+		`ID,4,4,1,4,1,1,1,4,2.5,1.2909944487358056,1.75,2.5,3.25,"1 (1), 2 (1), 3 (1), 4 (1)"`,
+		`Name,4,3,Sato,Yamada,4,6,,,,,,,,"Sato (2), Tanaka (1), Yamada (1)"`,
+		`Price,3,3,1.5,20,2,3,1.5,100,40.5,52.35217282978807,10.75,20,60,"1.5 (1), 100 (1), 20 (1)"`,
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestStatsCmd_approx(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,A",
+		"2,B",
+		"3,A",
+		"4,",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name",
+		"--approx",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Column,Count,Distinct,Min,Max,MinLength,MaxLength,NumericMin,NumericMax,Mean,Stddev,P25,Median,P75,Top5",
+		`Name,3,2,A,B,1,1,,,,,,,,"A (2), B (1)"`,
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestStatsCmd_empty(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Column,Count,Distinct,Min,Max,MinLength,MaxLength,NumericMin,NumericMax,Mean,Stddev,P25,Median,P75,Top5",
+		"ID,1,1,1,1,1,1,1,1,1,,1,1,1,1 (1)",
+		"Name,0,0,,,,,,,,,,,,",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestStatsCmd_format(t *testing.T) {
+
+	s := joinRows(
+		"ID\tName",
+		"1\tA",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name",
+		"--delim", `\t`,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Column\tCount\tDistinct\tMin\tMax\tMinLength\tMaxLength\tNumericMin\tNumericMax\tMean\tStddev\tP25\tMedian\tP75\tTop5",
+		"Name\t1\t1\tA\tA\t1\t1\t\t\t\t\t\t\t\tA (1)",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestStatsCmd_columnNotFound(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("ID", "1"))
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+		"-c", "Name",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "missing Name in the CSV file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestStatsCmd_inputFileEmpty(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"stats",
+		"-i", fi,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to read the CSV file: EOF" {
+		t.Fatal("failed test\n", err)
+	}
+}
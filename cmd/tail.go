@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newTailCmd() *cobra.Command {
+
+	tailCmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Show tail few rows",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			number, _ := cmd.Flags().GetInt("number")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			// 表示件数は1以上
+			if number <= 0 {
+				return fmt.Errorf("number must be greater than or equal to 1")
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runTail(
+				format,
+				inputPath,
+				number,
+				outputPath,
+				cmd.OutOrStdout())
+		},
+	}
+
+	tailCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	tailCmd.MarkFlagRequired("input")
+	tailCmd.Flags().IntP("number", "n", 10, "The number of records to show. If not specified, it will be the last 10 rows.")
+	tailCmd.Flags().StringP("output", "o", "", "(optional) Output CSV file path. If specified, the rows are output as CSV instead of a table.")
+
+	return tailCmd
+}
+
+func runTail(format csv.Format, inputPath string, number int, outputPath string, stdout io.Writer) error {
+
+	columnNames, rows, err := tail(format, inputPath, number)
+	if err != nil {
+		return err
+	}
+
+	if outputPath == "" {
+		table := newRowsTable(stdout, columnNames)
+		table.AppendBulk(rows)
+		table.Render()
+		return nil
+	}
+
+	writer, close, err := setupOutput(outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if err := writer.Write(columnNames); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
+}
+
+func tail(format csv.Format, inputPath string, number int) ([]string, [][]string, error) {
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer close()
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read the input CSV file")
+	}
+
+	if canSeekInput(format, inputPath) {
+		rows, ok, err := seekTail(format, inputPath, number, len(columnNames))
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			return columnNames, rows, nil
+		}
+	}
+
+	// 先頭から読んで、最後のN行を保持
+	rows := [][]string{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the input CSV file")
+		}
+
+		rows = append(rows, row)
+		if len(rows) > number {
+			rows = rows[1:]
+		}
+	}
+
+	return columnNames, rows, nil
+}
+
+// ファイルの途中から読むことができるか (圧縮やアーカイブ内のファイル、改行や引用符をバイト単位で判別できない形式は不可)
+// レコードの区切りは改行(LF)で判断するので、LFとCRLF以外の区切りも不可
+func canSeekInput(format csv.Format, inputPath string) bool {
+
+	if format.InputFormat != "" && format.InputFormat != csv.InputFormatCsv {
+		return false
+	}
+	if format.RecordSeparator != "" && format.RecordSeparator != "\n" && format.RecordSeparator != "\r\n" {
+		return false
+	}
+	if format.Encoding != nil || format.Quote >= 0x80 {
+		return false
+	}
+	if format.OnError != "" && format.OnError != csv.OnErrorFail {
+		return false
+	}
+	if _, _, ok := csv.SplitArchivePath(inputPath); ok {
+		return false
+	}
+
+	info, err := os.Stat(inputPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, csv.CompressionMagicLength)
+	n, _ := file.ReadAt(head, 0)
+	return csv.DetectCompression(head[:n]) == csv.CompressionNone
+}
+
+const tailChunkSize = 64 * 1024
+
+// 末尾からレコードの区切りを探して、最後のN行を読み込む
+// 区切りを特定できなかった場合(ファイルの先頭まで到達した場合や、読み込んだ結果が想定と異なる場合)は、okがfalse
+func seekTail(format csv.Format, inputPath string, number int, columnCount int) ([][]string, bool, error) {
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	size := info.Size()
+
+	quote := byte('"')
+	if format.Quote != 0 {
+		quote = byte(format.Quote)
+	}
+
+	// レコードの区切りとなる改行の直後の位置を、末尾側から順に集める
+	// 引用符で囲まれた中の改行と区別するため、その位置からファイル末尾までの引用符の数が偶数のものだけを区切りとみなす
+	// (ファイル全体で引用符の数は偶数なので、その位置より前の引用符の数も偶数となり、引用符の外にあることになる)
+	boundaries := []int64{}
+	quoteCount := 0
+	chunk := make([]byte, tailChunkSize)
+	end := size // この位置より前が未読
+
+	for end > 0 && len(boundaries) < number {
+		start := end - tailChunkSize
+		if start < 0 {
+			start = 0
+		}
+		buf := chunk[:end-start]
+		if _, err := file.ReadAt(buf, start); err != nil && err != io.EOF {
+			return nil, false, err
+		}
+
+		for i := len(buf) - 1; i >= 0 && len(boundaries) < number; i-- {
+			position := start + int64(i)
+			switch buf[i] {
+			case quote:
+				quoteCount++
+			case '\n':
+				// 末尾の改行は区切りとして数えない
+				if position+1 < size && quoteCount%2 == 0 {
+					boundaries = append(boundaries, position+1)
+				}
+			}
+			end = position
+		}
+	}
+
+	if len(boundaries) < number {
+		// 先頭まで到達 (ファイル全体を読み込むのと変わらない)
+		return nil, false, nil
+	}
+
+	rows, ok, err := readTailRows(file, format, boundaries[number-1], size, columnCount)
+	if err != nil || !ok || len(rows) != number {
+		return nil, false, err
+	}
+
+	return rows, true, nil
+}
+
+func readTailRows(file *os.File, format csv.Format, start int64, size int64, columnCount int) ([][]string, bool, error) {
+
+	// 途中から読むので、ヘッダとしては扱わない
+	format.NoHeader = false
+	format.DedupeHeaders = false
+
+	section := io.NewSectionReader(file, start, size-start)
+	reader := csv.NewCsvReader(section, format)
+
+	rows := [][]string{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// 区切りを誤って判断した可能性があるので、先頭から読み直す
+			// (実際に不正なファイルの場合は、先頭から読んだ際にエラーとなる)
+			return nil, false, nil
+		}
+		if len(row) != columnCount {
+			return nil, false, nil
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, true, nil
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/onozaty/csvt/csv"
+)
+
+func TestTailCmd(t *testing.T) {
+
+	s := `ID,Name,Company ID
+1,Yamada,1
+2,Ichikawa,
+3,"Hanako, Sato",3
+4,Otani,3
+5,,2
+6,Suzuki,1
+7,Jane,2
+8,Michel,3
+9,1,3
+10,Ken,3
+11,Suzuki,2
+12,Z,
+`
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", f,
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	except := `+----+--------------+------------+
+| ID | Name         | Company ID |
++----+--------------+------------+
+| 3  | Hanako, Sato | 3          |
+| 4  | Otani        | 3          |
+| 5  |              | 2          |
+| 6  | Suzuki       | 1          |
+| 7  | Jane         | 2          |
+| 8  | Michel       | 3          |
+| 9  | 1            | 3          |
+| 10 | Ken          | 3          |
+| 11 | Suzuki       | 2          |
+| 12 | Z            |            |
++----+--------------+------------+
+`
+	if result != except {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTailCmd_output(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		`2,"b`,
+		`c"`,
+		`3,"d""`,
+		`e"`,
+	)
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", fi,
+		"-n", "2",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	expect := joinRows(
+		"col1,col2",
+		`2,"b`,
+		`c"`,
+		`3,"d""`,
+		`e"`,
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTailCmd_overRows(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+	)
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", fi,
+		"-n", "5",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	if result != s {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTailCmd_compressed(t *testing.T) {
+
+	s := joinRows(
+		"col1,col2",
+		"1,a",
+		"2,b",
+		"3,c",
+	)
+	fi := createCompressedFile(t, s, csv.CompressionGzip)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", fi,
+		"-n", "2",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	expect := joinRows(
+		"col1,col2",
+		"2,b",
+		"3,c",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTailCmd_customSeparator(t *testing.T) {
+
+	// 改行はレコードの区切りではないので、先頭から読む
+	fi := createTempFile(t, "col1|a\n1|b\n2|c\n3|d\n4|")
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", fi,
+		"-n", "2",
+		"-o", fo,
+		"--sep", "|",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	expect := "col1|\"c\n3\"|\"d\n4\"|"
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTailCmd_invalidNumber(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", "input.csv",
+		"-n", "0",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "number must be greater than or equal to 1" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSeekTail_largeFile(t *testing.T) {
+
+	// 読み込み単位を超えるサイズで、引用符内の改行を含む
+	b := strings.Builder{}
+	b.WriteString("id,text\r\n")
+	for i := 1; i <= 10000; i++ {
+		b.WriteString(strconv.Itoa(i) + ",\"line1\r\nline2 \"\"" + strconv.Itoa(i) + "\"\"\"\r\n")
+	}
+	fi := createTempFile(t, b.String())
+	defer os.Remove(fi)
+
+	rows, ok, err := seekTail(csv.Format{}, fi, 3, 2)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if !ok {
+		t.Fatal("failed test\n", ok)
+	}
+
+	expect := [][]string{
+		{"9998", "line1\r\nline2 \"9998\""},
+		{"9999", "line1\r\nline2 \"9999\""},
+		{"10000", "line1\r\nline2 \"10000\""},
+	}
+	if !reflect.DeepEqual(rows, expect) {
+		t.Fatal("failed test\n", rows)
+	}
+}
+
+func TestSeekTail_wrongNumberOfFields(t *testing.T) {
+
+	s := "col1,col2\n1,a\n2,b\n3\n"
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	_, ok, err := seekTail(csv.Format{}, fi, 2, 2)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if ok {
+		t.Fatal("failed test\n", ok)
+	}
+}
+
+func TestTailCmd_wrongNumberOfFields(t *testing.T) {
+
+	s := "col1,col2\n1,a\n2,b\n3\n"
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"tail",
+		"-i", fi,
+		"-n", "2",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to read the input CSV file: parse error on record 4: wrong number of fields" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestSeekTail_reachStart(t *testing.T) {
+
+	s := "col1,col2\n1,a\n2,b\n"
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	_, ok, err := seekTail(csv.Format{}, fi, 3, 2)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if ok {
+		t.Fatal("failed test\n", ok)
+	}
+}
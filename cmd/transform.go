@@ -25,6 +25,15 @@ func newTransformCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			outputFormat.NoOutputHeader = inputFormat.NoOutputHeader
+			outputFormat.OutputFormat = inputFormat.OutputFormat
+			outputFormat.InferTypes = inputFormat.InferTypes
+			outputFormat.Schema = inputFormat.Schema
+			outputFormat.FixedWidthSpec = inputFormat.FixedWidthSpec
+			outputFormat.TableMaxWidth = inputFormat.TableMaxWidth
+			outputFormat.TablePageSize = inputFormat.TablePageSize
+			outputFormat.Compression = inputFormat.Compression
+			outputFormat.CompressionLevel = inputFormat.CompressionLevel
 
 			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
 			cmd.SilenceUsage = true
@@ -70,7 +79,12 @@ func runTransform(inputPath string, inputFormat csv.Format, outputPath string, o
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return outputClose()
 }
 
 func copy(reader csv.CsvReader, writer csv.CsvWriter) error {
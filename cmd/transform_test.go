@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/onozaty/csvt/csv"
 )
 
 func TestTransformCmd(t *testing.T) {
@@ -448,3 +452,382 @@ func TestTransformCmd_encoding_invalid(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestTransformCmd_noOutputHeader(t *testing.T) {
+
+	s := `ID,Name
+1,Yamada
+2,"Hanako, Sato"
+`
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fo,
+		"--out-delim", "\t",
+		"--no-output-header",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := "1\tYamada\r\n2\tHanako, Sato\r\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTransformCmd_xlsx(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,\"Hanako, Sato\"",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fx := createTempFile(t, "")
+	defer os.Remove(fx)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	// CSV -> xlsx
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fx,
+		"--output-format", "xlsx",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// xlsx -> CSV
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fx,
+		"-o", fo,
+		"--input-format", "xlsx",
+	})
+
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	if result != s {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTransformCmd_parquet(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Price",
+		"001,Yamada,10",
+		"002,\"Hanako, Sato\",",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": [{"name": "ID", "type": "string"}, {"name": "Price", "type": "integer"}]}`)
+	defer os.Remove(fs)
+
+	fp := createTempFile(t, "")
+	defer os.Remove(fp)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	// CSV -> Parquet
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fp,
+		"--output-format", "parquet",
+		"--parquet-schema", fs,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// Parquet -> CSV
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fp,
+		"-o", fo,
+		"--input-format", "parquet",
+	})
+
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	if result != s {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTransformCmd_fixed(t *testing.T) {
+
+	fi := createTempFile(t, "00001Yamada\r\n00120Sato  \r\n")
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": [{"name": "ID", "start": 1, "length": 5, "align": "right", "pad": "0"}, {"name": "Name", "start": 6, "length": 6}]}`)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fo,
+		"--input-format", "fixed",
+		"--fixed-spec", fs,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"120,Sato",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTransformCmd_fixedWithoutSpec(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", "input.txt",
+		"-o", "output.csv",
+		"--input-format", "fixed",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "flag fixed-spec is required for the fixed format" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestTransformCmd_compressed(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Sato",
+	)
+
+	compressions := []struct {
+		compression string
+		ext         string
+	}{
+		{csv.CompressionGzip, ".gz"},
+		{csv.CompressionZstd, ".zst"},
+		{csv.CompressionBzip2, ".bz2"},
+		{csv.CompressionXz, ".xz"},
+	}
+
+	for _, c := range compressions {
+
+		fi := createCompressedFile(t, s, c.compression)
+		defer os.Remove(fi)
+
+		d := createTempDir(t)
+		defer os.RemoveAll(d)
+
+		fo := filepath.Join(d, "output.csv"+c.ext)
+
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{
+			"transform",
+			"-i", fi,
+			"-o", fo,
+			"--out-delim", "\t",
+		})
+
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatal("failed test\n", c.compression, err)
+		}
+
+		if compression := csv.DetectCompression(readBytes(t, fo)); compression != c.compression {
+			t.Fatal("failed test\n", compression)
+		}
+
+		result := readDecompressedString(t, fo)
+
+		expect := joinRows(
+			"ID\tName",
+			"1\tYamada",
+			"2\tSato",
+		)
+
+		if result != expect {
+			t.Fatal("failed test\n", c.compression, result)
+		}
+	}
+}
+
+func TestTransformCmd_compressFlag(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+	)
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	fo := filepath.Join(d, "output.csv.gz")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fo,
+		"--compress", "none",
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 拡張子よりも指定が優先
+	result := readString(t, fo)
+	if result != s {
+		t.Fatal("failed test\n", result)
+	}
+
+	fo = filepath.Join(d, "output.csv")
+
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", fo,
+		"--compress", "zstd",
+		"--compress-level", "19",
+	})
+
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if compression := csv.DetectCompression(readBytes(t, fo)); compression != csv.CompressionZstd {
+		t.Fatal("failed test\n", compression)
+	}
+	result = readDecompressedString(t, fo)
+	if result != s {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTransformCmd_invalidCompress(t *testing.T) {
+
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"--compress", "lz4"}, "flag compress should be specified with gzip, zstd, bzip2, xz or none"},
+		{[]string{"--compress-level", "-1"}, "compress-level must be greater than or equal to 0"},
+	}
+
+	for _, test := range tests {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs(append([]string{"transform", "-i", "input.csv", "-o", "output.csv"}, test.args...))
+
+		err := rootCmd.Execute()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestTransformCmd_compressCloseError(t *testing.T) {
+
+	// 書き込みが常に失敗するファイル
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+
+	fi := createTempFile(t, joinRows("ID", "1"))
+	defer os.Remove(fi)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", "/dev/full",
+		"--compress", "zstd",
+	})
+
+	// zstdはCloseまで書き込まれないので、Closeのエラーが返ること
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no space left on device") {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestTransformCmd_invalidCompressLevel(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("ID", "1"))
+	defer os.Remove(fi)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"transform",
+		"-i", fi,
+		"-o", filepath.Join(d, "output.csv.gz"),
+		"--compress-level", "10",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "compression level of gzip should be 1 to 9" {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -60,7 +60,12 @@ func runUnique(format csv.Format, inputPath string, targetColumnNames []string,
 		return err
 	}
 
-	return writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return close()
 }
 
 func unique(reader csv.CsvReader, targetColumnNames []string, writer csv.CsvWriter) error {
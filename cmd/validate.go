@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+func newValidateCmd() *cobra.Command {
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate by schema",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			schemaPath, _ := cmd.Flags().GetString("schema")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			schema, err := csv.LoadSchema(schemaPath)
+			if err != nil {
+				return err
+			}
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runValidate(
+				format,
+				inputPath,
+				schema,
+				outputPath)
+		},
+	}
+
+	validateCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	validateCmd.MarkFlagRequired("input")
+	validateCmd.Flags().StringP("schema", "s", "", "Schema file path. JSON or YAML(.yaml, .yml) can be used.")
+	validateCmd.MarkFlagRequired("schema")
+	validateCmd.Flags().StringP("output", "o", "", "Output CSV file path for violations.")
+	validateCmd.MarkFlagRequired("output")
+
+	return validateCmd
+}
+
+func runValidate(format csv.Format, inputPath string, schema *csv.Schema, outputPath string) error {
+
+	reader, writer, close, err := setupInputOutput(inputPath, outputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	count, err := validate(reader, schema, writer)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = close()
+	if err != nil {
+		return err
+	}
+
+	// 違反があった場合は、終了コードで判断できるようにエラーとする
+	if count > 0 {
+		return fmt.Errorf("%d violations found", count)
+	}
+
+	return nil
+}
+
+func validate(reader csv.CsvReader, schema *csv.Schema, writer csv.CsvWriter) (int, error) {
+
+	// ヘッダ
+	columnNames, err := reader.Read()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read the CSV file")
+	}
+
+	err = writer.Write([]string{"Row", "Column", "Value", "Rule"})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	report := func(rowNum string, columnName string, value string, rule string) error {
+		count++
+		return writer.Write([]string{rowNum, columnName, value, rule})
+	}
+
+	// CSVに存在するカラムのみ値の検証を行う
+	targetColumns := []*csv.ColumnSchema{}
+	targetColumnIndexes := []int{}
+	uniqueItemSets := map[string]*csv.ItemSet{}
+	for _, column := range schema.Columns {
+
+		columnIndex := slices.Index(columnNames, column.Name)
+		if columnIndex == -1 {
+			if column.Required {
+				// ヘッダに対する違反なので行番号は無し
+				if err := report("", column.Name, "", "required"); err != nil {
+					return 0, err
+				}
+			}
+			continue
+		}
+		// スキーマのカラム名はそのままの名前なので、どのカラムか区別できない
+		if slices.Contains(columnNames[columnIndex+1:], column.Name) {
+			return 0, fmt.Errorf("%s is duplicated in the header (use --dedupe-headers)", column.Name)
+		}
+
+		targetColumns = append(targetColumns, column)
+		targetColumnIndexes = append(targetColumnIndexes, columnIndex)
+		if column.Unique {
+			uniqueItemSets[column.Name] = csv.NewItemSet()
+		}
+	}
+
+	rowNum := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to read the CSV file")
+		}
+
+		rowNum++
+
+		for i, column := range targetColumns {
+			value := row[targetColumnIndexes[i]]
+
+			for _, rule := range column.Validate(value) {
+				if err := report(strconv.Itoa(rowNum), column.Name, value, rule); err != nil {
+					return 0, err
+				}
+			}
+
+			// 一意性は空の値を除いて判断
+			if itemSet, ok := uniqueItemSets[column.Name]; ok && value != "" {
+				if itemSet.Contains(value) {
+					if err := report(strconv.Itoa(rowNum), column.Name, value, "unique"); err != nil {
+						return 0, err
+					}
+				} else {
+					itemSet.Add(value)
+				}
+			}
+		}
+	}
+
+	return count, nil
+}
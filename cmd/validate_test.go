@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidateCmd(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Price,Date,Status,Code",
+		"1,Yamada,100,2022-01-02,active,A1",
+		"2,Sato,1.5,2022-02-30,deleted,A2",
+		"x,,abc,2022/03/01,active,B3",
+		"2,Suzuki Ichiro,-.5,,,A4",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	schema := `{
+  "columns": [
+    {"name": "ID", "type": "integer", "required": true, "notNull": true, "unique": true},
+    {"name": "Name", "notNull": true, "maxLength": 10},
+    {"name": "Price", "type": "decimal"},
+    {"name": "Date", "type": "date", "layout": "2006-01-02"},
+    {"name": "Status", "type": "enum", "values": ["active", "inactive"]},
+    {"name": "Code", "type": "regex", "pattern": "^A[0-9]$"},
+    {"name": "Missing", "required": true},
+    {"name": "Optional"}
+  ]
+}`
+	fs := createTempFile(t, schema)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "10 violations found" {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Row,Column,Value,Rule",
+		",Missing,,required",
+		"2,Date,2022-02-30,date",
+		"2,Status,deleted,enum",
+		"3,ID,x,integer",
+		"3,Name,,notNull",
+		"3,Price,abc,decimal",
+		"3,Date,2022/03/01,date",
+		"3,Code,B3,regex",
+		"4,ID,2,unique",
+		"4,Name,Suzuki Ichiro,maxLength",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestValidateCmd_duplicateHeader(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name,Name",
+		"1,Yamada,x",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	schema := `{"columns": [{"name": "Name", "type": "integer"}]}`
+	fs := createTempFile(t, schema)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "Name is duplicated in the header (use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 一意にした名前で指定できる
+	schema = `{"columns": [{"name": "Name_2", "type": "integer"}]}`
+	fs2 := createTempFile(t, schema)
+	defer os.Remove(fs2)
+
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs2,
+		"-o", fo,
+		"--dedupe-headers",
+	})
+
+	err = rootCmd.Execute()
+	if err == nil || err.Error() != "1 violations found" {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+	expect := joinRows(
+		"Row,Column,Value,Rule",
+		"1,Name_2,x,integer",
+	)
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestValidateCmd_yaml(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Sato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	schema := `
+columns:
+  - name: ID
+    type: int
+    unique: true
+  - name: Name
+    maxLength: 6
+`
+	fs := createTempFile(t, schema)
+	defer os.Remove(fs)
+	os.Rename(fs, fs+".yaml")
+	defer os.Remove(fs + ".yaml")
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs + ".yaml",
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Row,Column,Value,Rule",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestValidateCmd_format(t *testing.T) {
+
+	s := joinRows(
+		"ID\tName",
+		"1\tYamada",
+		"a\tSato",
+	)
+
+	fi := createTempFile(t, s)
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": [{"name": "ID", "type": "integer"}]}`)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+		"--delim", `\t`,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "1 violations found" {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, fo)
+
+	expect := joinRows(
+		"Row\tColumn\tValue\tRule",
+		"2\tID\ta\tinteger",
+	)
+
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestValidateCmd_invalidSchema(t *testing.T) {
+
+	fi := createTempFile(t, joinRows("ID", "1"))
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": [{"name": "ID", "type": "number"}]}`)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "type number of the column ID is invalid" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestValidateCmd_inputFileNotFound(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": []}`)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi + "____", // 存在しないファイル
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	pathErr := err.(*os.PathError)
+	if pathErr.Path != fi+"____" || pathErr.Op != "open" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestValidateCmd_inputFileEmpty(t *testing.T) {
+
+	fi := createTempFile(t, "")
+	defer os.Remove(fi)
+
+	fs := createTempFile(t, `{"columns": []}`)
+	defer os.Remove(fs)
+
+	fo := createTempFile(t, "")
+	defer os.Remove(fo)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"validate",
+		"-i", fi,
+		"-s", fs,
+		"-o", fo,
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "failed to read the CSV file: EOF" {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/onozaty/csvt/csv"
+	"github.com/spf13/cobra"
+)
+
+func newViewCmd() *cobra.Command {
+
+	viewCmd := &cobra.Command{
+		Use:     "view",
+		Aliases: []string{"table"},
+		Short:   "Render as a table",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			format, err := getFlagBaseCsvFormat(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			inputPath, _ := cmd.Flags().GetString("input")
+			outputPath, _ := cmd.Flags().GetString("output")
+			tableFormat, _ := cmd.Flags().GetString("format")
+
+			switch tableFormat {
+			case csv.OutputFormatText, csv.OutputFormatMarkdown, csv.OutputFormatHtml:
+			default:
+				return fmt.Errorf("format should be specified with text, markdown or html")
+			}
+			format.OutputFormat = tableFormat
+
+			// 引数の解析に成功した時点で、エラーが起きてもUsageは表示しない
+			cmd.SilenceUsage = true
+
+			return runView(
+				format,
+				inputPath,
+				outputPath,
+				cmd.OutOrStdout())
+		},
+	}
+
+	viewCmd.Flags().StringP("input", "i", "", "Input CSV file path.")
+	viewCmd.MarkFlagRequired("input")
+	viewCmd.Flags().StringP("output", "o", "", "(optional) Output file path. If not specified, it will be output to the standard output.")
+	viewCmd.Flags().StringP("format", "f", "text", "(optional) Table format. text, markdown or html can be specified.")
+
+	return viewCmd
+}
+
+func runView(format csv.Format, inputPath string, outputPath string, stdout io.Writer) error {
+
+	if outputPath != "" {
+		// 出力先が指定された場合は、変換と同じ
+		return runTransform(inputPath, format, outputPath, format)
+	}
+
+	reader, close, err := setupInput(inputPath, format)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	writer := csv.NewCsvWriter(stdout, format)
+
+	if err := copy(reader, writer); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
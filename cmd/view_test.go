@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestViewCmd(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,山田",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"view",
+		"-i", f,
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := "ID  Name\n" +
+		"--  ------\n" +
+		"1   Yamada\n" +
+		"2   山田\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestViewCmd_noOutputHeader(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,山田",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"view",
+		"-i", f,
+		"--no-output-header",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := "1  Yamada\n" +
+		"2  山田\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestViewCmd_markdown(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+		"2,Hanako Sato",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"table",
+		"-i", f,
+		"-f", "markdown",
+		"--table-max-width", "8",
+	})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := buf.String()
+
+	expect := "| ID  | Name     |\n" +
+		"| --- | -------- |\n" +
+		"| 1   | Yamada   |\n" +
+		"| 2   | Hanak... |\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestViewCmd_html_output(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,<a>",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	d := createTempDir(t)
+	defer os.RemoveAll(d)
+
+	o := filepath.Join(d, "output.html")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"view",
+		"-i", f,
+		"-f", "html",
+		"-o", o,
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	result := readString(t, o)
+
+	expect := "<table>\n<thead>\n<tr><th>ID</th><th>Name</th></tr>\n</thead>\n<tbody>\n" +
+		"<tr><td>1</td><td>&lt;a&gt;</td></tr>\n" +
+		"</tbody>\n</table>\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestViewCmd_invalidFormat(t *testing.T) {
+
+	s := joinRows(
+		"ID,Name",
+		"1,Yamada",
+	)
+	f := createTempFile(t, s)
+	defer os.Remove(f)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"view",
+		"-i", f,
+		"-f", "json",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "format should be specified with text, markdown or html" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestViewCmd_fileNotFound(t *testing.T) {
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"view",
+		"-i", "not_found.csv",
+	})
+
+	err := rootCmd.Execute()
+	if err == nil || err.Error() != "open not_found.csv: no such file or directory" {
+		t.Fatal("failed test\n", err)
+	}
+}
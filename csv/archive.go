@@ -0,0 +1,305 @@
+package csv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+)
+
+// アーカイブ内のファイルを指定する際の区切り (archive.zip!/dir/file.csv)
+const ArchiveSeparator = "!/"
+
+// アーカイブのパスと、アーカイブ内のパスに分割
+func SplitArchivePath(p string) (string, string, bool) {
+
+	index := strings.Index(p, ArchiveSeparator)
+	if index == -1 {
+		return "", "", false
+	}
+
+	if _, err := os.Stat(p); err == nil {
+		// "!/"を含む名前のファイルが実在する場合はそちらを優先
+		return "", "", false
+	}
+
+	return p[:index], p[index+len(ArchiveSeparator):], true
+}
+
+func HasGlobPattern(p string) bool {
+
+	return strings.ContainsAny(p, "*?[")
+}
+
+// アーカイブ内のファイルのうち、パターンに一致するものを名前順に返す
+// ファイル名がUTF-8で無い場合は、指定されたエンコーディングで解釈する
+func MatchArchiveMembers(archivePath string, pattern string, nameEncoding encoding.Encoding) ([]string, error) {
+
+	archive, err := openArchive(archivePath, nameEncoding)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	names, err := archive.Names()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern = strings.TrimPrefix(pattern, "/")
+	matched := []string{}
+	for _, name := range names {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %s", pattern)
+		}
+		if ok {
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+
+	return matched, nil
+}
+
+// アーカイブ内のファイルを開く
+func OpenArchiveMember(archivePath string, member string, nameEncoding encoding.Encoding) (io.Reader, func(), error) {
+
+	archive, err := openArchive(archivePath, nameEncoding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := archive.Open(strings.TrimPrefix(member, "/"))
+	if err != nil {
+		archive.Close()
+		return nil, nil, err
+	}
+
+	return r, func() { archive.Close() }, nil
+}
+
+type archiveReader interface {
+	Names() ([]string, error)
+	Open(name string) (io.Reader, error)
+	Close() error
+}
+
+func openArchive(archivePath string, nameEncoding encoding.Encoding) (archiveReader, error) {
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 4)
+	n, _ := file.ReadAt(head, 0)
+	if bytes.HasPrefix(head[:n], []byte("PK")) {
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		zr, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "invalid zip file %s", archivePath)
+		}
+		return &zipArchive{file: file, zr: zr, path: archivePath, nameEncoding: nameEncoding}, nil
+	}
+
+	file.Close()
+	return &tarArchive{path: archivePath, nameEncoding: nameEncoding}, nil
+}
+
+type zipArchive struct {
+	file         *os.File
+	zr           *zip.Reader
+	path         string
+	nameEncoding encoding.Encoding
+	opened       io.ReadCloser
+}
+
+func (a *zipArchive) Names() ([]string, error) {
+
+	names := []string{}
+	for _, f := range a.zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, err := a.name(f)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (a *zipArchive) Open(name string) (io.Reader, error) {
+
+	for _, f := range a.zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		fileName, err := a.name(f)
+		if err != nil {
+			return nil, err
+		}
+		if fileName == name {
+			r, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			a.opened = r
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not found in %s", name, a.path)
+}
+
+func (a *zipArchive) Close() error {
+
+	if a.opened != nil {
+		a.opened.Close()
+	}
+	return a.file.Close()
+}
+
+func (a *zipArchive) name(f *zip.File) (string, error) {
+
+	// UTF-8のフラグが無くても、UTF-8として正しい名前はそのまま使う
+	// (UTF-8のフラグを付けずにUTF-8で格納するツールもあるため)
+	if !f.NonUTF8 || utf8.ValidString(f.Name) {
+		return f.Name, nil
+	}
+	return decodeArchiveName(f.Name, a.nameEncoding)
+}
+
+// tarは先頭から順に読むしかないので、開くたびに読み直す
+type tarArchive struct {
+	path         string
+	nameEncoding encoding.Encoding
+	file         *os.File
+	decompressor io.ReadCloser
+}
+
+func (a *tarArchive) Names() ([]string, error) {
+
+	names := []string{}
+	err := a.walk(func(name string, r *tar.Reader) (bool, error) {
+		names = append(names, name)
+		return false, nil
+	})
+	return names, err
+}
+
+func (a *tarArchive) Open(name string) (io.Reader, error) {
+
+	var found io.Reader
+	err := a.walk(func(fileName string, r *tar.Reader) (bool, error) {
+		if fileName == name {
+			found = r
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s is not found in %s", name, a.path)
+	}
+
+	return found, nil
+}
+
+func (a *tarArchive) Close() error {
+
+	a.closeFile()
+	return nil
+}
+
+func (a *tarArchive) closeFile() {
+
+	if a.decompressor != nil {
+		a.decompressor.Close()
+		a.decompressor = nil
+	}
+	if a.file != nil {
+		a.file.Close()
+		a.file = nil
+	}
+}
+
+// ファイルごとにfnを呼び出す (fnがtrueを返した時点で終了し、ファイルは開いたままにする)
+func (a *tarArchive) walk(fn func(name string, r *tar.Reader) (bool, error)) error {
+
+	a.closeFile()
+
+	file, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	a.file = file
+
+	// tar.gzなどの圧縮されたものも扱えるように
+	br := bufio.NewReader(file)
+	head, _ := br.Peek(CompressionMagicLength)
+	decompressor, err := NewDecompressReader(br, DetectCompression(head))
+	if err != nil {
+		return errors.Wrapf(err, "failed to decompress %s", a.path)
+	}
+	a.decompressor = decompressor
+
+	tr := tar.NewReader(decompressor)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invalid archive file %s (zip or tar is supported)", a.path)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := header.Name
+		if !utf8.ValidString(name) {
+			name, err = decodeArchiveName(name, a.nameEncoding)
+			if err != nil {
+				return err
+			}
+		}
+
+		done, err := fn(strings.TrimPrefix(name, "./"), tr)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+func decodeArchiveName(name string, nameEncoding encoding.Encoding) (string, error) {
+
+	if nameEncoding == nil {
+		return name, nil
+	}
+
+	decoded, err := nameEncoding.NewDecoder().String(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode the file name %s", name)
+	}
+	return decoded, nil
+}
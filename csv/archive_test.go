@@ -0,0 +1,204 @@
+package csv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func createZipFile(t *testing.T, files map[string]string, nonUTF8 bool) string {
+
+	b := &bytes.Buffer{}
+	zw := zip.NewWriter(b)
+	for name, content := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, NonUTF8: nonUTF8})
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	return createTempFile(t, b.String())
+}
+
+func createTarGzFile(t *testing.T, files map[string]string) string {
+
+	b := &bytes.Buffer{}
+	cw, err := NewCompressWriter(b, CompressionGzip, 0)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	tw := tar.NewWriter(cw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	return createTempFile(t, b.String())
+}
+
+func readArchiveMember(t *testing.T, archivePath string, member string, format Format) string {
+
+	r, close, err := OpenArchiveMember(archivePath, member, format.Encoding)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	defer close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	return string(data)
+}
+
+func TestSplitArchivePath(t *testing.T) {
+
+	archivePath, member, ok := SplitArchivePath("dir/data.zip!/2024/sales.csv")
+	if !ok || archivePath != "dir/data.zip" || member != "2024/sales.csv" {
+		t.Fatal("failed test\n", archivePath, member, ok)
+	}
+
+	_, _, ok = SplitArchivePath("dir/data.csv")
+	if ok {
+		t.Fatal("failed test\n")
+	}
+}
+
+func TestZipArchive(t *testing.T) {
+
+	f := createZipFile(t, map[string]string{
+		"2024/b.csv": "b",
+		"2024/a.csv": "a",
+		"2023/c.csv": "c",
+	}, false)
+	defer os.Remove(f)
+
+	matched, err := MatchArchiveMembers(f, "2024/*.csv", nil)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if !reflect.DeepEqual(matched, []string{"2024/a.csv", "2024/b.csv"}) {
+		t.Fatal("failed test\n", matched)
+	}
+
+	result := readArchiveMember(t, f, "/2023/c.csv", Format{})
+	if result != "c" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestZipArchive_shiftJisName(t *testing.T) {
+
+	name, err := japanese.ShiftJIS.NewEncoder().String("売上/東京.csv")
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	f := createZipFile(t, map[string]string{
+		name: "ID\r\n1\r\n",
+	}, true)
+	defer os.Remove(f)
+
+	matched, err := MatchArchiveMembers(f, "売上/*", japanese.ShiftJIS)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if !reflect.DeepEqual(matched, []string{"売上/東京.csv"}) {
+		t.Fatal("failed test\n", matched)
+	}
+
+	result := readArchiveMember(t, f, "売上/東京.csv", Format{Encoding: japanese.ShiftJIS})
+	if result != "ID\r\n1\r\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestZipArchive_utf8NameWithoutFlag(t *testing.T) {
+
+	// UTF-8のフラグが無いUTF-8の名前は、文字コードの指定があってもそのまま
+	f := createZipFile(t, map[string]string{
+		"売上/東京.csv": "ID\r\n1\r\n",
+	}, true)
+	defer os.Remove(f)
+
+	matched, err := MatchArchiveMembers(f, "売上/*", japanese.ShiftJIS)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if !reflect.DeepEqual(matched, []string{"売上/東京.csv"}) {
+		t.Fatal("failed test\n", matched)
+	}
+
+	result := readArchiveMember(t, f, "売上/東京.csv", Format{Encoding: japanese.ShiftJIS})
+	if result != "ID\r\n1\r\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTarArchive(t *testing.T) {
+
+	f := createTarGzFile(t, map[string]string{
+		"./a.csv":   "a",
+		"dir/b.csv": "b",
+	})
+	defer os.Remove(f)
+
+	matched, err := MatchArchiveMembers(f, "*.csv", nil)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	if !reflect.DeepEqual(matched, []string{"a.csv"}) {
+		t.Fatal("failed test\n", matched)
+	}
+
+	result := readArchiveMember(t, f, "dir/b.csv", Format{})
+	if result != "b" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestOpenArchiveMember_notFound(t *testing.T) {
+
+	f := createZipFile(t, map[string]string{
+		"a.csv": "a",
+	}, false)
+	defer os.Remove(f)
+
+	_, _, err := OpenArchiveMember(f, "b.csv", nil)
+	if err == nil || err.Error() != "b.csv is not found in "+f {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestOpenArchiveMember_notArchive(t *testing.T) {
+
+	f := createTempFile(t, "a,b\r\n1,2\r\n")
+	defer os.Remove(f)
+
+	_, _, err := OpenArchiveMember(f, "a.csv", nil)
+	if err == nil || err.Error() != "invalid archive file "+f+" (zip or tar is supported): unexpected EOF" {
+		t.Fatal("failed test\n", err)
+	}
+}
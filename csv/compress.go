@@ -0,0 +1,203 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	CompressionNone  = "none"
+	CompressionGzip  = "gzip"
+	CompressionZstd  = "zstd"
+	CompressionBzip2 = "bzip2"
+	CompressionXz    = "xz"
+)
+
+var compressionMagics = []struct {
+	compression string
+	magic       []byte
+}{
+	{CompressionGzip, []byte{0x1f, 0x8b}},
+	{CompressionZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{CompressionBzip2, []byte("BZh")},
+	{CompressionXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+}
+
+// 判定に必要な先頭のバイト数
+const CompressionMagicLength = 6
+
+var compressionExtensions = map[string]string{
+	".gz":   CompressionGzip,
+	".gzip": CompressionGzip,
+	".zst":  CompressionZstd,
+	".zstd": CompressionZstd,
+	".bz2":  CompressionBzip2,
+	".xz":   CompressionXz,
+}
+
+// 先頭のバイト列(マジックナンバー)から圧縮形式を判定
+func DetectCompression(head []byte) string {
+
+	for _, m := range compressionMagics {
+		if bytes.HasPrefix(head, m.magic) {
+			if m.compression == CompressionBzip2 && (len(head) < 4 || head[3] < '1' || head[3] > '9') {
+				// "BZh"の後はブロックサイズ('1'〜'9')なので、それ以外は"BZh"で始まるテキストとみなす
+				continue
+			}
+			return m.compression
+		}
+	}
+	return CompressionNone
+}
+
+// 拡張子から圧縮形式を判定
+func CompressionByExtension(path string) string {
+
+	if compression, ok := compressionExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return compression
+	}
+	return CompressionNone
+}
+
+// 圧縮形式の拡張子を除いたパスと、その拡張子
+func SplitCompressionExtension(path string) (string, string) {
+
+	ext := filepath.Ext(path)
+	if _, ok := compressionExtensions[strings.ToLower(ext)]; ok {
+		return path[:len(path)-len(ext)], ext
+	}
+	return path, ""
+}
+
+func NewDecompressReader(r io.Reader, compression string) (io.ReadCloser, error) {
+
+	switch compression {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case CompressionBzip2:
+		return bzip2.NewReader(r, nil)
+	case CompressionXz:
+		reader, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(reader), nil
+	default:
+		return nil, fmt.Errorf("compression %s is not supported", compression)
+	}
+}
+
+// 圧縮して書き込むWriter (Closeで圧縮の終端が書き込まれる)
+// levelに0を指定した場合は、それぞれの既定のレベル
+func NewCompressWriter(w io.Writer, compression string, level int) (io.WriteCloser, error) {
+
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		} else if level < gzip.BestSpeed || level > gzip.BestCompression {
+			return nil, fmt.Errorf("compression level of gzip should be 1 to 9")
+		}
+		return gzip.NewWriterLevel(w, level)
+	case CompressionZstd:
+		encoderLevel := zstd.SpeedDefault
+		if level != 0 {
+			if level < 1 || level > 22 {
+				return nil, fmt.Errorf("compression level of zstd should be 1 to 22")
+			}
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(encoderLevel))
+	case CompressionBzip2:
+		if level == 0 {
+			level = bzip2.DefaultCompression
+		} else if level < bzip2.BestSpeed || level > bzip2.BestCompression {
+			return nil, fmt.Errorf("compression level of bzip2 should be 1 to 9")
+		}
+		return bzip2.NewWriter(w, &bzip2.WriterConfig{Level: level})
+	case CompressionXz:
+		if level != 0 {
+			return nil, fmt.Errorf("compression level cannot be specified for xz")
+		}
+		return xz.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("compression %s is not supported", compression)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// --usingfile の一時ファイルに格納する値の圧縮 (nilの場合は圧縮しない)
+// 値ごとに圧縮するため、出力の圧縮形式によらずzstdを使う
+type tempValueCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newTempValueCodec(compress bool) (*tempValueCodec, error) {
+
+	if !compress {
+		return nil, nil
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		encoder.Close()
+		return nil, err
+	}
+
+	return &tempValueCodec{encoder: encoder, decoder: decoder}, nil
+}
+
+func (c *tempValueCodec) encode(value []byte) []byte {
+
+	if c == nil {
+		return value
+	}
+	return c.encoder.EncodeAll(value, nil)
+}
+
+func (c *tempValueCodec) decode(value []byte) ([]byte, error) {
+
+	if c == nil {
+		return value, nil
+	}
+	return c.decoder.DecodeAll(value, nil)
+}
+
+func (c *tempValueCodec) Close() {
+
+	if c == nil {
+		return
+	}
+	c.encoder.Close()
+	c.decoder.Close()
+}
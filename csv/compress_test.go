@@ -0,0 +1,139 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+
+	compressions := []string{CompressionGzip, CompressionZstd, CompressionBzip2, CompressionXz, CompressionNone}
+
+	for _, compression := range compressions {
+
+		b := &bytes.Buffer{}
+		w, err := NewCompressWriter(b, compression, 0)
+		if err != nil {
+			t.Fatal("failed test\n", compression, err)
+		}
+		if _, err := w.Write([]byte("a,b\r\n1,2\r\n")); err != nil {
+			t.Fatal("failed test\n", compression, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("failed test\n", compression, err)
+		}
+
+		detected := DetectCompression(b.Bytes())
+		if detected != compression {
+			t.Fatal("failed test\n", compression, detected)
+		}
+
+		r, err := NewDecompressReader(b, detected)
+		if err != nil {
+			t.Fatal("failed test\n", compression, err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal("failed test\n", compression, err)
+		}
+		r.Close()
+
+		if string(data) != "a,b\r\n1,2\r\n" {
+			t.Fatal("failed test\n", compression, string(data))
+		}
+	}
+}
+
+func TestCompress_level(t *testing.T) {
+
+	tests := []struct {
+		compression string
+		level       int
+	}{
+		{CompressionGzip, 9},
+		{CompressionZstd, 19},
+		{CompressionBzip2, 1},
+	}
+
+	for _, test := range tests {
+		_, err := NewCompressWriter(&bytes.Buffer{}, test.compression, test.level)
+		if err != nil {
+			t.Fatal("failed test\n", test.compression, err)
+		}
+	}
+}
+
+func TestCompress_invalidLevel(t *testing.T) {
+
+	tests := []struct {
+		compression string
+		level       int
+		expect      string
+	}{
+		{CompressionGzip, 10, "compression level of gzip should be 1 to 9"},
+		{CompressionZstd, 23, "compression level of zstd should be 1 to 22"},
+		{CompressionBzip2, 10, "compression level of bzip2 should be 1 to 9"},
+		{CompressionXz, 1, "compression level cannot be specified for xz"},
+		{"lz4", 0, "compression lz4 is not supported"},
+	}
+
+	for _, test := range tests {
+		_, err := NewCompressWriter(&bytes.Buffer{}, test.compression, test.level)
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestDetectCompression_none(t *testing.T) {
+
+	tests := [][]byte{
+		[]byte("a,b\r\n"),
+		{0x1f},
+		{},
+		[]byte("BZh,a\r\n"), // bzip2はブロックサイズ('1'〜'9')まで確認
+		[]byte("BZh"),
+	}
+
+	for _, test := range tests {
+		if compression := DetectCompression(test); compression != CompressionNone {
+			t.Fatal("failed test\n", compression)
+		}
+	}
+}
+
+func TestCompressionByExtension(t *testing.T) {
+
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{"a.csv.gz", CompressionGzip},
+		{"a.csv.GZ", CompressionGzip},
+		{"a.csv.zst", CompressionZstd},
+		{"a.csv.bz2", CompressionBzip2},
+		{"dir/a.xz", CompressionXz},
+		{"a.csv", CompressionNone},
+		{"a", CompressionNone},
+	}
+
+	for _, test := range tests {
+		if compression := CompressionByExtension(test.path); compression != test.expect {
+			t.Fatal("failed test\n", test.path, compression)
+		}
+	}
+}
+
+func TestSplitCompressionExtension(t *testing.T) {
+
+	path, ext := SplitCompressionExtension("dir/a.csv.gz")
+	if path != "dir/a.csv" || ext != ".gz" {
+		t.Fatal("failed test\n", path, ext)
+	}
+
+	path, ext = SplitCompressionExtension("dir/a.csv")
+	if path != "dir/a.csv" || ext != "" {
+		t.Fatal("failed test\n", path, ext)
+	}
+}
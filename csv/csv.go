@@ -3,6 +3,7 @@ package csv
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/onozaty/go-customcsv"
@@ -19,8 +20,35 @@ type Format struct {
 	AllQuotes       bool
 	WithBom         bool
 	Encoding        encoding.Encoding
+	OnError         string
+	OnErrorLogPath  string
+	NoHeader        bool
+	NoOutputHeader  bool
+	DedupeHeaders   bool
+	OutputFormat    string
+	InferTypes      bool
+	InputFormat     string
+	JsonArray       string
+	Sheet           string
+	Schema          *Schema
+	FixedWidthSpec  *FixedWidthSpec
+	TableMaxWidth   int
+	TablePageSize   int
+	// 出力の圧縮形式 (空の場合は出力先の拡張子で判断)
+	Compression      string
+	CompressionLevel int
+	// --on-error log=FILE の書き込み先 (コマンドの実行時に開いたもの)
+	OnErrorLogWriter io.Writer
 }
 
+// 不正なレコードがあった場合の振る舞い
+const (
+	OnErrorFail = "fail"
+	OnErrorSkip = "skip"
+	OnErrorPad  = "pad"
+	OnErrorLog  = "log"
+)
+
 type CsvReader interface {
 	Read() (record []string, err error)
 }
@@ -33,6 +61,42 @@ var utf8bom = []byte{0xEF, 0xBB, 0xBF}
 
 func NewCsvReader(r io.Reader, f Format) CsvReader {
 
+	switch f.InputFormat {
+	case InputFormatJson, InputFormatNdjson:
+		// JSONはキーがヘッダになるので、ヘッダ関連の指定は無視
+		return newJsonReader(r, f)
+	case InputFormatXlsx:
+		return newXlsxReader(r, f)
+	case InputFormatParquet:
+		return newParquetReader(r)
+	case InputFormatFixed:
+		return newFixedWidthReader(r, f)
+	}
+
+	var reader CsvReader
+	switch f.OnError {
+	case OnErrorSkip, OnErrorPad, OnErrorLog:
+		reader = &errorPolicyReader{
+			scanner: NewRecordScanner(r, f),
+			onError: f.OnError,
+			log:     f.OnErrorLogWriter,
+		}
+	default:
+		reader = newCustomReader(r, f)
+	}
+
+	if f.NoHeader {
+		reader = &headerlessReader{r: reader}
+	}
+	if f.DedupeHeaders {
+		reader = &dedupeHeaderReader{r: reader}
+	}
+
+	return reader
+}
+
+func newCustomReader(r io.Reader, f Format) *customcsv.Reader {
+
 	if f.Encoding != nil {
 		r = transform.NewReader(r, f.Encoding.NewDecoder())
 	}
@@ -54,6 +118,35 @@ func NewCsvReader(r io.Reader, f Format) CsvReader {
 
 func NewCsvWriter(w io.Writer, f Format) CsvWriter {
 
+	switch f.OutputFormat {
+	case OutputFormatJson, OutputFormatNdjson:
+		// JSONはUTF-8固定で、ヘッダはキーとして使うので出力有無の指定は無視
+		return newJsonWriter(w, f)
+	case OutputFormatXlsx:
+		// 文字コードの指定は無視 (ヘッダは1行目として出力)
+		if f.NoOutputHeader {
+			return &headerlessWriter{w: newXlsxWriter(w, f)}
+		}
+		return newXlsxWriter(w, f)
+	case OutputFormatParquet:
+		return newParquetWriter(w, f)
+	case OutputFormatFixed:
+		return newFixedWidthWriter(w, f)
+	case OutputFormatMarkdown, OutputFormatHtml, OutputFormatText:
+		return newTableWriter(w, f)
+	}
+
+	cw := newCustomWriter(w, f)
+
+	if f.NoOutputHeader {
+		return &headerlessWriter{w: cw}
+	}
+
+	return cw
+}
+
+func newCustomWriter(w io.Writer, f Format) *customcsv.Writer {
+
 	if f.Encoding != nil {
 		w = transform.NewWriter(w, f.Encoding.NewEncoder())
 	}
@@ -77,6 +170,82 @@ func NewCsvWriter(w io.Writer, f Format) CsvWriter {
 	return cw
 }
 
+// ヘッダが無いCSVに対して、カラム番号("1", "2", ...)をヘッダとして返すReader
+type headerlessReader struct {
+	r           CsvReader
+	headerRead  bool
+	firstRecord []string
+}
+
+func (r *headerlessReader) Read() ([]string, error) {
+
+	if !r.headerRead {
+		// 1レコード目を読んで、項目数分のカラム名を作成
+		record, err := r.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.headerRead = true
+		r.firstRecord = record
+
+		return ColumnNumbers(len(record)), nil
+	}
+
+	if r.firstRecord != nil {
+		record := r.firstRecord
+		r.firstRecord = nil
+		return record, nil
+	}
+
+	return r.r.Read()
+}
+
+func ColumnNumbers(count int) []string {
+
+	names := []string{}
+	for i := 1; i <= count; i++ {
+		names = append(names, strconv.Itoa(i))
+	}
+	return names
+}
+
+// ヘッダ内の重複したカラム名に連番を付与するReader
+type dedupeHeaderReader struct {
+	r          CsvReader
+	headerRead bool
+}
+
+func (r *dedupeHeaderReader) Read() ([]string, error) {
+
+	record, err := r.r.Read()
+	if err != nil || r.headerRead {
+		return record, err
+	}
+
+	r.headerRead = true
+	return DedupeColumnNames(record), nil
+}
+
+// ヘッダ(最初のレコード)を出力しないWriter
+type headerlessWriter struct {
+	w             CsvWriter
+	headerSkipped bool
+}
+
+func (w *headerlessWriter) Write(record []string) error {
+
+	if !w.headerSkipped {
+		w.headerSkipped = true
+		return nil
+	}
+
+	return w.w.Write(record)
+}
+
+func (w *headerlessWriter) Flush() error {
+	return w.w.Flush()
+}
+
 func Encoding(name string) (encoding.Encoding, error) {
 
 	if slices.Contains([]string{"utf8", "utf-8"}, strings.ToLower(name)) {
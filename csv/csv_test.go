@@ -338,3 +338,83 @@ func TestEncoding_invalid(t *testing.T) {
 		t.Fatal("failed test\n", err)
 	}
 }
+
+func TestNewCsvReader_noHeader(t *testing.T) {
+
+	s := "1,Yamada\n2,Sato\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{NoHeader: true})
+
+	expects := [][]string{
+		{"1", "2"}, // カラム番号がヘッダに
+		{"1", "Yamada"},
+		{"2", "Sato"},
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestNewCsvReader_noHeaderEmpty(t *testing.T) {
+
+	r := NewCsvReader(strings.NewReader(""), Format{NoHeader: true})
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestNewCsvWriter_noOutputHeader(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{NoOutputHeader: true})
+
+	w.Write([]string{"ID", "Name"})
+	w.Write([]string{"1", "Yamada"})
+	w.Write([]string{"2", "Sato"})
+	w.Flush()
+
+	if b.String() != "1,Yamada\r\n2,Sato\r\n" {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestNewCsvReader_dedupeHeaders(t *testing.T) {
+
+	s := "name,ID,name,name\n1,2,3,4\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{DedupeHeaders: true})
+
+	expects := [][]string{
+		{"name", "ID", "name_2", "name_3"},
+		{"1", "2", "3", "4"},
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
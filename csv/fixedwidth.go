@@ -0,0 +1,324 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	InputFormatFixed  = "fixed"
+	OutputFormatFixed = "fixed"
+)
+
+const (
+	AlignLeft  = "left"
+	AlignRight = "right"
+)
+
+// 固定長のカラム定義 (位置と長さはエンコード後のバイト数)
+type FixedWidthSpec struct {
+	Columns []*FixedWidthColumn `json:"columns" yaml:"columns"`
+}
+
+type FixedWidthColumn struct {
+	Name   string `json:"name" yaml:"name"`
+	Start  int    `json:"start" yaml:"start"`
+	Length int    `json:"length" yaml:"length"`
+	Align  string `json:"align,omitempty" yaml:"align,omitempty"`
+	Pad    string `json:"pad,omitempty" yaml:"pad,omitempty"`
+}
+
+func LoadFixedWidthSpec(path string) (*FixedWidthSpec, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &FixedWidthSpec{}
+
+	// スキーマと同じく拡張子でYAMLかJSONかを判断
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(data, spec)
+	} else {
+		err = json.Unmarshal(data, spec)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid fixed-width spec file %s", path)
+	}
+
+	if err := spec.Compile(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func (s *FixedWidthSpec) Compile() error {
+
+	if len(s.Columns) == 0 {
+		return fmt.Errorf("no column in the fixed-width spec")
+	}
+
+	for _, c := range s.Columns {
+
+		if c.Name == "" {
+			return fmt.Errorf("column name is not specified in the fixed-width spec")
+		}
+		if c.Start < 1 {
+			return fmt.Errorf("start of the column %s must be greater than or equal to 1", c.Name)
+		}
+		if c.Length < 1 {
+			return fmt.Errorf("length of the column %s must be greater than or equal to 1", c.Name)
+		}
+
+		switch c.Align {
+		case "":
+			c.Align = AlignLeft
+		case AlignLeft, AlignRight:
+		default:
+			return fmt.Errorf("align of the column %s should be left or right", c.Name)
+		}
+
+		if c.Pad == "" {
+			c.Pad = " "
+		}
+		if utf8.RuneCountInString(c.Pad) != 1 {
+			return fmt.Errorf("pad of the column %s should be one character", c.Name)
+		}
+	}
+
+	// 書き込み時に位置が重ならないように
+	sorted := append([]*FixedWidthColumn{}, s.Columns...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Start+sorted[i-1].Length > sorted[i].Start {
+			return fmt.Errorf("column %s overlaps with the column %s", sorted[i].Name, sorted[i-1].Name)
+		}
+	}
+
+	return nil
+}
+
+func (s *FixedWidthSpec) columnNames() []string {
+
+	names := []string{}
+	for _, c := range s.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// 1行を1レコードとして、カラム定義の位置で切り出すReader
+type fixedWidthReader struct {
+	r          *bufio.Reader
+	spec       *FixedWidthSpec
+	encoding   encoding.Encoding
+	headerRead bool
+}
+
+func newFixedWidthReader(r io.Reader, f Format) *fixedWidthReader {
+
+	br := bufio.NewReader(r)
+	if f.Encoding == nil {
+		// UTF-8の場合はBOMを除去
+		if head, err := br.Peek(len(utf8bom)); err == nil && bytes.Equal(head, utf8bom) {
+			br.Discard(len(utf8bom))
+		}
+	}
+
+	return &fixedWidthReader{
+		r:        br,
+		spec:     f.FixedWidthSpec,
+		encoding: f.Encoding,
+	}
+}
+
+func (r *fixedWidthReader) Read() ([]string, error) {
+
+	if !r.headerRead {
+		r.headerRead = true
+		return r.spec.columnNames(), nil
+	}
+
+	line, err := r.r.ReadBytes('\n')
+	if err == io.EOF && len(line) == 0 {
+		return nil, io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+
+	record := []string{}
+	for _, c := range r.spec.Columns {
+
+		// 行が短い場合は、足りない部分は空として扱う
+		start := c.Start - 1
+		end := start + c.Length
+		if start > len(line) {
+			start = len(line)
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+
+		value, err := r.decode(line[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		trimmed := strings.TrimRight(value, c.Pad)
+		if c.Align == AlignRight {
+			trimmed = strings.TrimLeft(value, c.Pad)
+		}
+		if trimmed == "" && value != "" && c.Pad != " " {
+			// 全てパディング文字の場合(0埋めの0など)は、値として1文字残す
+			trimmed = c.Pad
+		}
+		record = append(record, trimmed)
+	}
+
+	return record, nil
+}
+
+func (r *fixedWidthReader) decode(b []byte) (string, error) {
+
+	if r.encoding == nil {
+		return string(b), nil
+	}
+
+	decoded, err := r.encoding.NewDecoder().Bytes(b)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// カラム定義の位置と長さで1行に書き込むWriter
+// 1レコード目はヘッダとして、カラム定義の名前との対応付けに使う
+type fixedWidthWriter struct {
+	w               *bufio.Writer
+	spec            *FixedWidthSpec
+	encoding        encoding.Encoding
+	recordSeparator string
+	columnIndexes   []int
+	width           int
+}
+
+func newFixedWidthWriter(w io.Writer, f Format) *fixedWidthWriter {
+
+	recordSeparator := f.RecordSeparator
+	if recordSeparator == "" {
+		recordSeparator = "\r\n"
+	}
+
+	width := 0
+	for _, c := range f.FixedWidthSpec.Columns {
+		if c.Start-1+c.Length > width {
+			width = c.Start - 1 + c.Length
+		}
+	}
+
+	return &fixedWidthWriter{
+		w:               bufio.NewWriter(w),
+		spec:            f.FixedWidthSpec,
+		encoding:        f.Encoding,
+		recordSeparator: recordSeparator,
+		width:           width,
+	}
+}
+
+func (w *fixedWidthWriter) Write(record []string) error {
+
+	if w.columnIndexes == nil {
+		columnIndexes := []int{}
+		for _, c := range w.spec.Columns {
+			index := -1
+			for i, name := range record {
+				if name == c.Name {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				return fmt.Errorf("column %s in the fixed-width spec is not found", c.Name)
+			}
+			columnIndexes = append(columnIndexes, index)
+		}
+		w.columnIndexes = columnIndexes
+		return nil
+	}
+
+	// カラムの間は空白で埋める
+	line := bytes.Repeat([]byte(" "), w.width)
+
+	for i, c := range w.spec.Columns {
+
+		value := ""
+		if w.columnIndexes[i] < len(record) {
+			value = record[w.columnIndexes[i]]
+		}
+
+		field, err := w.encode(value)
+		if err != nil {
+			return err
+		}
+		if len(field) > c.Length {
+			return fmt.Errorf("value %s of the column %s exceeds the length %d", value, c.Name, c.Length)
+		}
+
+		pad, err := w.encode(c.Pad)
+		if err != nil {
+			return err
+		}
+		padding := bytes.Repeat(pad, (c.Length-len(field))/len(pad))
+		if len(field)+len(padding) != c.Length {
+			return fmt.Errorf("value %s of the column %s cannot be padded to the length %d", value, c.Name, c.Length)
+		}
+
+		if c.Align == AlignRight {
+			field = append(padding, field...)
+		} else {
+			field = append(field, padding...)
+		}
+		copy(line[c.Start-1:], field)
+	}
+
+	if _, err := w.w.Write(line); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString(w.recordSeparator)
+	return err
+}
+
+func (w *fixedWidthWriter) Flush() error {
+
+	return w.w.Flush()
+}
+
+func (w *fixedWidthWriter) encode(value string) ([]byte, error) {
+
+	if w.encoding == nil {
+		return []byte(value), nil
+	}
+
+	encoded, err := w.encoding.NewEncoder().Bytes([]byte(value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "value %s cannot be encoded", value)
+	}
+	return encoded, nil
+}
@@ -0,0 +1,192 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestLoadFixedWidthSpec(t *testing.T) {
+
+	path := createTempFile(t, `{"columns": [
+  {"name": "ID", "start": 1, "length": 5, "align": "right", "pad": "0"},
+  {"name": "Name", "start": 6, "length": 10}
+]}`)
+	defer os.Remove(path)
+
+	spec, err := LoadFixedWidthSpec(path)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	expect := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "ID", Start: 1, Length: 5, Align: AlignRight, Pad: "0"},
+			{Name: "Name", Start: 6, Length: 10, Align: AlignLeft, Pad: " "},
+		},
+	}
+	if !reflect.DeepEqual(spec, expect) {
+		t.Fatal("failed test\n", spec)
+	}
+}
+
+func TestFixedWidthSpec_invalid(t *testing.T) {
+
+	tests := []struct {
+		columns []*FixedWidthColumn
+		expect  string
+	}{
+		{[]*FixedWidthColumn{}, "no column in the fixed-width spec"},
+		{[]*FixedWidthColumn{{Start: 1, Length: 1}}, "column name is not specified in the fixed-width spec"},
+		{[]*FixedWidthColumn{{Name: "a", Start: 0, Length: 1}}, "start of the column a must be greater than or equal to 1"},
+		{[]*FixedWidthColumn{{Name: "a", Start: 1, Length: 0}}, "length of the column a must be greater than or equal to 1"},
+		{[]*FixedWidthColumn{{Name: "a", Start: 1, Length: 1, Align: "center"}}, "align of the column a should be left or right"},
+		{[]*FixedWidthColumn{{Name: "a", Start: 1, Length: 1, Pad: "ab"}}, "pad of the column a should be one character"},
+		{[]*FixedWidthColumn{{Name: "a", Start: 1, Length: 3}, {Name: "b", Start: 3, Length: 1}}, "column b overlaps with the column a"},
+	}
+
+	for _, test := range tests {
+		spec := &FixedWidthSpec{Columns: test.columns}
+		err := spec.Compile()
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
+
+func TestFixedWidthReader(t *testing.T) {
+
+	spec := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "ID", Start: 1, Length: 5, Align: AlignRight, Pad: "0"},
+			{Name: "Name", Start: 6, Length: 6, Align: AlignLeft, Pad: " "},
+			{Name: "Note", Start: 13, Length: 3, Align: AlignLeft, Pad: " "},
+		},
+	}
+
+	// Shift_JISでは全角1文字が2バイト
+	s, _ := japanese.ShiftJIS.NewEncoder().String("00001山田  -abc\r\n00120ABCDEF\n")
+
+	r := NewCsvReader(strings.NewReader(s), Format{InputFormat: InputFormatFixed, FixedWidthSpec: spec, Encoding: japanese.ShiftJIS})
+
+	expects := [][]string{
+		{"ID", "Name", "Note"},
+		{"1", "山田", "abc"},
+		{"120", "ABCDEF", ""}, // 行が短い場合は空
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestFixedWidthReader_allPad(t *testing.T) {
+
+	spec := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "ID", Start: 1, Length: 5, Align: AlignRight, Pad: "0"},
+			{Name: "Code", Start: 6, Length: 3, Align: AlignLeft, Pad: "*"},
+			{Name: "Name", Start: 9, Length: 3, Align: AlignLeft, Pad: " "},
+		},
+	}
+
+	s := "00000***   \n00010A**ABC\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{InputFormat: InputFormatFixed, FixedWidthSpec: spec})
+
+	expects := [][]string{
+		{"ID", "Code", "Name"},
+		{"0", "*", ""}, // 全てパディング文字の場合は1文字残す (スペースは空)
+		{"10", "A", "ABC"},
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestFixedWidthWriter(t *testing.T) {
+
+	spec := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "ID", Start: 1, Length: 5, Align: AlignRight, Pad: "0"},
+			{Name: "Name", Start: 6, Length: 6, Align: AlignLeft, Pad: " "},
+			{Name: "Note", Start: 13, Length: 3, Align: AlignLeft, Pad: " "},
+		},
+	}
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatFixed, FixedWidthSpec: spec, Encoding: japanese.ShiftJIS, RecordSeparator: "\n"})
+
+	// ヘッダの名前で対応付け
+	w.Write([]string{"Note", "Name", "ID"})
+	w.Write([]string{"abc", "山田", "1"})
+	w.Write([]string{"", "ABCDEF", "120"})
+	w.Flush()
+
+	expect, _ := japanese.ShiftJIS.NewEncoder().String("00001山田   abc\n00120ABCDEF    \n")
+
+	if b.String() != expect {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestFixedWidthWriter_exceedsLength(t *testing.T) {
+
+	spec := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "Name", Start: 1, Length: 5, Align: AlignLeft, Pad: " "},
+		},
+	}
+
+	w := NewCsvWriter(&bytes.Buffer{}, Format{OutputFormat: OutputFormatFixed, FixedWidthSpec: spec, Encoding: japanese.ShiftJIS})
+
+	w.Write([]string{"Name"})
+	err := w.Write([]string{"山田太"}) // 6バイト
+	if err == nil || err.Error() != "value 山田太 of the column Name exceeds the length 5" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestFixedWidthWriter_columnNotFound(t *testing.T) {
+
+	spec := &FixedWidthSpec{
+		Columns: []*FixedWidthColumn{
+			{Name: "Name", Start: 1, Length: 5, Align: AlignLeft, Pad: " "},
+		},
+	}
+
+	w := NewCsvWriter(&bytes.Buffer{}, Format{OutputFormat: OutputFormatFixed, FixedWidthSpec: spec})
+
+	err := w.Write([]string{"ID"})
+	if err == nil || err.Error() != "column Name in the fixed-width spec is not found" {
+		t.Fatal("failed test\n", err)
+	}
+}
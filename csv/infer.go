@@ -0,0 +1,175 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+)
+
+// 日付として推定する際に試すレイアウト
+var InferDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	time.RFC3339,
+}
+
+type columnInference struct {
+	integer     bool
+	decimal     bool
+	boolean     bool
+	dateLayouts []string
+	count       int
+	nullCount   int
+	maxLength   int
+	items       *ItemSet
+	duplicated  bool
+}
+
+func newColumnInference() *columnInference {
+	return &columnInference{
+		integer:     true,
+		decimal:     true,
+		boolean:     true,
+		dateLayouts: slices.Clone(InferDateLayouts),
+		items:       NewItemSet(),
+	}
+}
+
+func (c *columnInference) add(value string) {
+
+	c.count++
+
+	if value == "" {
+		c.nullCount++
+		return
+	}
+
+	// 先頭が0の数値(00123など)は、数値にすると値が変わるので文字列として扱う
+	if hasLeadingZero(value) {
+		c.integer = false
+		c.decimal = false
+	}
+	if c.integer && !integerPattern.MatchString(value) {
+		c.integer = false
+	}
+	if c.decimal && !decimalPattern.MatchString(value) {
+		c.decimal = false
+	}
+	if c.boolean && !isBoolean(value) {
+		c.boolean = false
+	}
+
+	// 一致しなかったレイアウトは候補から外していく
+	layouts := []string{}
+	for _, layout := range c.dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			layouts = append(layouts, layout)
+		}
+	}
+	c.dateLayouts = layouts
+
+	if length := utf8.RuneCountInString(value); length > c.maxLength {
+		c.maxLength = length
+	}
+
+	if !c.duplicated {
+		if c.items.Contains(value) {
+			// 重複したら以降は値を保持する必要がない
+			c.duplicated = true
+			c.items = nil
+		} else {
+			c.items.Add(value)
+		}
+	}
+}
+
+func (c *columnInference) schema(name string) *ColumnSchema {
+
+	column := &ColumnSchema{
+		Name:     name,
+		Type:     TypeString,
+		Required: true,
+	}
+
+	hasValue := c.count > c.nullCount
+	if hasValue {
+		switch {
+		case c.integer:
+			column.Type = TypeInteger
+		case c.decimal:
+			column.Type = TypeDecimal
+		case c.boolean:
+			column.Type = TypeBoolean
+		case len(c.dateLayouts) != 0:
+			column.Type = TypeDate
+			column.Layout = c.dateLayouts[0]
+		}
+
+		column.NotNull = c.nullCount == 0
+		column.Unique = !c.duplicated
+		column.MaxLength = c.maxLength
+	}
+
+	nullRatio := 0.0
+	if c.count > 0 {
+		nullRatio = float64(c.nullCount) / float64(c.count)
+	}
+	column.NullRatio = &nullRatio
+
+	return column
+}
+
+// 先頭に余分な0がある数値か (0や0.5は該当しない)
+func hasLeadingZero(value string) bool {
+
+	value = strings.TrimLeft(value, "+-")
+	return len(value) >= 2 && value[0] == '0' && '0' <= value[1] && value[1] <= '9'
+}
+
+// 日付のレイアウトが時刻を含むか
+func layoutHasTime(layout string) bool {
+
+	date := time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)
+	return date.Format(layout) != date.Add(13*time.Hour+14*time.Minute+15*time.Second).Format(layout)
+}
+
+// CSVの内容からスキーマを推定する
+// maxRowsに0以下を指定した場合は全行を対象とする
+func InferSchema(reader CsvReader, maxRows int) (*Schema, error) {
+
+	columnNames, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	inferences := []*columnInference{}
+	for range columnNames {
+		inferences = append(inferences, newColumnInference())
+	}
+
+	for rowNum := 0; maxRows <= 0 || rowNum < maxRows; rowNum++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i, inference := range inferences {
+			inference.add(row[i])
+		}
+	}
+
+	schema := &Schema{}
+	for i, columnName := range columnNames {
+		schema.Columns = append(schema.Columns, inferences[i].schema(columnName))
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+
+	s := `A,B,C,D,E,F,G
+1,1.0,a,2022-01-02 03:04:05,,007,0
+-2,2,a,2022-01-03 03:04:05,,10,0.5
+`
+
+	schema, err := InferSchema(NewCsvReader(strings.NewReader(s), Format{}), 0)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	expects := []struct {
+		typ     string
+		layout  string
+		notNull bool
+		unique  bool
+	}{
+		{TypeInteger, "", true, true},
+		{TypeDecimal, "", true, true},
+		{TypeString, "", true, false},
+		{TypeDate, "2006-01-02 15:04:05", true, true},
+		{TypeString, "", false, false}, // 値が無い場合
+		{TypeString, "", true, true},   // 先頭が0の数値は文字列
+		{TypeDecimal, "", true, true},
+	}
+
+	for i, expect := range expects {
+		column := schema.Columns[i]
+		if column.Type != expect.typ || column.Layout != expect.layout || column.NotNull != expect.notNull || column.Unique != expect.unique {
+			t.Fatal("failed test\n", column)
+		}
+	}
+
+	// 推定したスキーマはそのまま検証に使えること
+	if err := schema.Compile(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestInferSchema_maxRows(t *testing.T) {
+
+	s := `A
+1
+x
+`
+
+	schema, err := InferSchema(NewCsvReader(strings.NewReader(s), Format{}), 1)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if schema.Columns[0].Type != TypeInteger {
+		t.Fatal("failed test\n", schema.Columns[0])
+	}
+}
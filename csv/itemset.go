@@ -1,10 +1,7 @@
 package csv
 
 import (
-	"fmt"
 	"io"
-
-	"golang.org/x/exp/slices"
 )
 
 type ItemSet struct {
@@ -39,9 +36,9 @@ func LoadItemSet(reader CsvReader, targetColumnName string) (*ItemSet, error) {
 	if err != nil {
 		return nil, err
 	}
-	targetColumnIndex := slices.Index(columnNames, targetColumnName)
-	if targetColumnIndex == -1 {
-		return nil, fmt.Errorf("%s is not found", targetColumnName)
+	targetColumnIndex, err := ColumnIndex(columnNames, targetColumnName)
+	if err != nil {
+		return nil, err
 	}
 
 	itemSet := NewItemSet()
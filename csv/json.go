@@ -0,0 +1,396 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 出力形式
+const (
+	OutputFormatCsv    = "csv"
+	OutputFormatJson   = "json"
+	OutputFormatNdjson = "ndjson"
+)
+
+// JSONの数値として扱える形式 (先頭0埋めのものなどは文字列のまま)
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// 1レコード目をヘッダとして、以降のレコードをヘッダ名をキーとしたオブジェクトで出力するWriter
+type jsonWriter struct {
+	w          *bufio.Writer
+	ndjson     bool
+	inferTypes bool
+	keys       [][]byte
+	count      int
+	closed     bool
+}
+
+func newJsonWriter(w io.Writer, f Format) *jsonWriter {
+
+	return &jsonWriter{
+		w:          bufio.NewWriter(w),
+		ndjson:     f.OutputFormat == OutputFormatNdjson,
+		inferTypes: f.InferTypes,
+	}
+}
+
+func (w *jsonWriter) Write(record []string) error {
+
+	if w.keys == nil {
+		// キーが重複するとオブジェクトとして読み込めないので一意に
+		w.keys = [][]byte{}
+		for _, name := range DedupeColumnNames(record) {
+			w.keys = append(w.keys, marshalJsonString(name))
+		}
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	if !w.ndjson {
+		if w.count == 0 {
+			buf.WriteString("[\n")
+		} else {
+			buf.WriteString(",\n")
+		}
+	}
+
+	buf.WriteByte('{')
+	for i, key := range w.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+		buf.Write(w.marshalValue(value))
+	}
+	buf.WriteByte('}')
+
+	if w.ndjson {
+		buf.WriteByte('\n')
+	}
+
+	w.count++
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
+
+func (w *jsonWriter) Flush() error {
+
+	if !w.ndjson && !w.closed {
+		// 配列を閉じる
+		w.closed = true
+		end := "\n]\n"
+		if w.count == 0 {
+			end = "[]\n"
+		}
+		if _, err := w.w.WriteString(end); err != nil {
+			return err
+		}
+	}
+
+	return w.w.Flush()
+}
+
+func (w *jsonWriter) marshalValue(value string) []byte {
+
+	if w.inferTypes {
+		switch {
+		case value == "":
+			return []byte("null")
+		case value == "true" || value == "false":
+			return []byte(value)
+		case jsonNumberPattern.MatchString(value):
+			return []byte(value)
+		}
+	}
+
+	return marshalJsonString(value)
+}
+
+func marshalJsonString(value string) []byte {
+
+	// HTMLのエスケープは不要
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// 入力形式
+const (
+	InputFormatCsv    = "csv"
+	InputFormatJson   = "json"
+	InputFormatNdjson = "ndjson"
+)
+
+// JSONの配列の扱い
+const (
+	JsonArrayJson  = "json"
+	JsonArrayIndex = "index"
+	JsonArrayJoin  = "join"
+)
+
+// JSONの配列もしくはNDJSONを読み込み、オブジェクトをレコードとして返すReader
+// ヘッダは全オブジェクトのキーの和集合となるため、読み込みは最初にまとめて行う
+type jsonReader struct {
+	r         io.Reader
+	ndjson    bool
+	arrayMode string
+	records   [][]string
+	loaded    bool
+	index     int
+}
+
+func newJsonReader(r io.Reader, f Format) *jsonReader {
+
+	arrayMode := f.JsonArray
+	if arrayMode == "" {
+		arrayMode = JsonArrayJson
+	}
+
+	return &jsonReader{
+		r:         r,
+		ndjson:    f.InputFormat == InputFormatNdjson,
+		arrayMode: arrayMode,
+	}
+}
+
+func (r *jsonReader) Read() ([]string, error) {
+
+	if !r.loaded {
+		r.loaded = true
+		records, err := r.load()
+		if err != nil {
+			return nil, err
+		}
+		r.records = records
+	}
+
+	if r.index >= len(r.records) {
+		return nil, io.EOF
+	}
+
+	record := r.records[r.index]
+	r.index++
+	return record, nil
+}
+
+func (r *jsonReader) load() ([][]string, error) {
+
+	br := bufio.NewReader(r.r)
+	// BOMがあると読み込めないので除去
+	if head, err := br.Peek(len(utf8bom)); err == nil && bytes.Equal(head, utf8bom) {
+		br.Discard(len(utf8bom))
+	}
+
+	dec := json.NewDecoder(br)
+
+	if !r.ndjson {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return [][]string{}, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid JSON")
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("JSON should be an array of objects")
+		}
+	}
+
+	columnNames := []string{}
+	columnIndexes := map[string]int{}
+	objects := []*jsonFields{}
+
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON (record %d)", len(objects)+1)
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || raw[0] != '{' {
+			return nil, fmt.Errorf("record %d is not a JSON object", len(objects)+1)
+		}
+
+		fields := &jsonFields{values: map[string]string{}}
+		if err := r.flatten(raw, "", fields); err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON (record %d)", len(objects)+1)
+		}
+
+		// キーは出現順でヘッダに
+		for _, name := range fields.names {
+			if _, has := columnIndexes[name]; !has {
+				columnIndexes[name] = len(columnNames)
+				columnNames = append(columnNames, name)
+			}
+		}
+		objects = append(objects, fields)
+	}
+
+	if !r.ndjson {
+		if _, err := dec.Token(); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON")
+		}
+	}
+
+	if len(objects) == 0 {
+		return [][]string{}, nil
+	}
+
+	records := [][]string{columnNames}
+	for _, fields := range objects {
+		record := make([]string, len(columnNames))
+		for name, value := range fields.values {
+			record[columnIndexes[name]] = value
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+type jsonFields struct {
+	names  []string
+	values map[string]string
+}
+
+func (f *jsonFields) set(name string, value string) {
+
+	if _, has := f.values[name]; !has {
+		f.names = append(f.names, name)
+	}
+	f.values[name] = value
+}
+
+// ネストしたオブジェクトはドット区切りのキーに展開
+func (r *jsonReader) flatten(raw json.RawMessage, prefix string, fields *jsonFields) error {
+
+	raw = bytes.TrimSpace(raw)
+
+	switch raw[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.Token() // {
+
+		empty := true
+		for dec.More() {
+			empty = false
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			if err := r.flatten(value, joinJsonKey(prefix, token.(string)), fields); err != nil {
+				return err
+			}
+		}
+
+		if empty && prefix != "" {
+			fields.set(prefix, "{}")
+		}
+		return nil
+
+	case '[':
+		switch r.arrayMode {
+		case JsonArrayIndex:
+			// 要素ごとに番号付きのキーに展開
+			var elements []json.RawMessage
+			if err := json.Unmarshal(raw, &elements); err != nil {
+				return err
+			}
+			if len(elements) == 0 {
+				fields.set(prefix, "")
+			}
+			for i, element := range elements {
+				if err := r.flatten(element, joinJsonKey(prefix, strconv.Itoa(i)), fields); err != nil {
+					return err
+				}
+			}
+			return nil
+
+		case JsonArrayJoin:
+			// 要素をカンマ区切りで連結
+			var elements []json.RawMessage
+			if err := json.Unmarshal(raw, &elements); err != nil {
+				return err
+			}
+			values := []string{}
+			for _, element := range elements {
+				value, err := jsonScalarString(element)
+				if err != nil {
+					return err
+				}
+				values = append(values, value)
+			}
+			fields.set(prefix, strings.Join(values, ","))
+			return nil
+
+		default:
+			// JSONの文字列のまま
+			value, err := jsonScalarString(raw)
+			if err != nil {
+				return err
+			}
+			fields.set(prefix, value)
+			return nil
+		}
+
+	default:
+		value, err := jsonScalarString(raw)
+		if err != nil {
+			return err
+		}
+		fields.set(prefix, value)
+		return nil
+	}
+}
+
+func joinJsonKey(prefix string, key string) string {
+
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// 文字列は値そのまま、nullは空文字、それ以外はJSONの表記で返す
+func jsonScalarString(raw json.RawMessage) (string, error) {
+
+	raw = bytes.TrimSpace(raw)
+
+	switch raw[0] {
+	case '"':
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", err
+		}
+		return value, nil
+	case 'n':
+		return "", nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, raw); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
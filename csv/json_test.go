@@ -0,0 +1,195 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJsonWriter(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatJson})
+
+	w.Write([]string{"ID", "Name", "Note"})
+	w.Write([]string{"1", "Yamada", "<a&b>"})
+	w.Write([]string{"02", "\"Sato\"", ""})
+	w.Flush()
+
+	expect := "[\n" +
+		`{"ID":"1","Name":"Yamada","Note":"<a&b>"},` + "\n" +
+		`{"ID":"02","Name":"\"Sato\"","Note":""}` + "\n" +
+		"]\n"
+
+	if b.String() != expect {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestJsonWriter_empty(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatJson})
+
+	w.Write([]string{"ID", "Name"})
+	w.Flush()
+
+	if b.String() != "[]\n" {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestJsonWriter_ndjson(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatNdjson, InferTypes: true})
+
+	w.Write([]string{"a", "b", "c", "d", "e", "f"})
+	w.Write([]string{"1", "-1.5e3", "true", "", "007", "TRUE"})
+	w.Write([]string{"x", "1.", "false", "null", "0", "1,000"})
+	w.Flush()
+
+	expect := `{"a":1,"b":-1.5e3,"c":true,"d":null,"e":"007","f":"TRUE"}` + "\n" +
+		`{"a":"x","b":"1.","c":false,"d":"null","e":0,"f":"1,000"}` + "\n"
+
+	if b.String() != expect {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestJsonWriter_duplicateHeader(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatNdjson})
+
+	w.Write([]string{"a", "a"})
+	w.Write([]string{"1", "2"})
+	w.Flush()
+
+	if b.String() != `{"a":"1","a_2":"2"}`+"\n" {
+		t.Fatal("failed test\n", b.String())
+	}
+}
+
+func TestJsonReader(t *testing.T) {
+
+	s := "\uFEFF" + `[
+  {"id": 1, "name": "Yamada", "address": {"city": "Tokyo", "zip": "100"}},
+  {"id": 2, "name": null, "active": true, "tags": ["a", "b"], "score": 1.50}
+]`
+
+	r := NewCsvReader(strings.NewReader(s), Format{InputFormat: InputFormatJson})
+
+	expects := [][]string{
+		{"id", "name", "address.city", "address.zip", "active", "tags", "score"},
+		{"1", "Yamada", "Tokyo", "100", "", "", ""},
+		{"2", "", "", "", "true", `["a","b"]`, "1.50"},
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestJsonReader_ndjson(t *testing.T) {
+
+	s := `{"id": 1, "tags": ["a", "b"], "items": [{"x": 1}, {"x": 2}]}
+{"id": 2, "tags": [], "empty": {}}
+`
+
+	tests := []struct {
+		jsonArray string
+		expects   [][]string
+	}{
+		{
+			JsonArrayIndex,
+			[][]string{
+				{"id", "tags.0", "tags.1", "items.0.x", "items.1.x", "tags", "empty"},
+				{"1", "a", "b", "1", "2", "", ""},
+				{"2", "", "", "", "", "", "{}"},
+			},
+		},
+		{
+			JsonArrayJoin,
+			[][]string{
+				{"id", "tags", "items", "empty"},
+				{"1", "a,b", `{"x":1},{"x":2}`, ""},
+				{"2", "", "", "{}"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		r := NewCsvReader(strings.NewReader(s), Format{InputFormat: InputFormatNdjson, JsonArray: test.jsonArray})
+
+		for _, expect := range test.expects {
+			record, err := r.Read()
+			if err != nil {
+				t.Fatal("failed test\n", test.jsonArray, err)
+			}
+			if !reflect.DeepEqual(record, expect) {
+				t.Fatal("failed test\n", test.jsonArray, record)
+			}
+		}
+
+		_, err := r.Read()
+		if err != io.EOF {
+			t.Fatal("failed test\n", test.jsonArray, err)
+		}
+	}
+}
+
+func TestJsonReader_empty(t *testing.T) {
+
+	for _, s := range []string{"", "[]"} {
+		r := NewCsvReader(strings.NewReader(s), Format{InputFormat: InputFormatJson})
+
+		_, err := r.Read()
+		if err != io.EOF {
+			t.Fatal("failed test\n", s, err)
+		}
+	}
+}
+
+func TestJsonReader_notArray(t *testing.T) {
+
+	r := NewCsvReader(strings.NewReader(`{"id": 1}`), Format{InputFormat: InputFormatJson})
+
+	_, err := r.Read()
+	if err == nil || err.Error() != "JSON should be an array of objects" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestJsonReader_notObject(t *testing.T) {
+
+	r := NewCsvReader(strings.NewReader("{\"id\": 1}\n[1]\n"), Format{InputFormat: InputFormatNdjson})
+
+	_, err := r.Read()
+	if err == nil || err.Error() != "record 2 is not a JSON object" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestJsonReader_invalid(t *testing.T) {
+
+	r := NewCsvReader(strings.NewReader("{\"id\": 1}\n{\"id\": \n"), Format{InputFormat: InputFormatNdjson})
+
+	_, err := r.Read()
+	if err == nil || err.Error() != "invalid JSON (record 2): unexpected EOF" {
+		t.Fatal("failed test\n", err)
+	}
+}
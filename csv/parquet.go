@@ -0,0 +1,546 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/types"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	InputFormatParquet  = "parquet"
+	OutputFormatParquet = "parquet"
+)
+
+// 一度に読み込む行数
+const parquetReadRows = 1000
+
+// 行グループの大きさ (これを超えたらファイルに書き出す)
+const parquetRowGroupSize = 32 * 1024 * 1024
+
+// Parquetのファイルを読み込み、各カラムの値を文字列にしたレコードとして返すReader
+type parquetReader struct {
+	r           io.Reader
+	pr          *reader.ParquetReader
+	columnNames []string
+	elements    []*parquet.SchemaElement
+	remaining   int64
+	records     [][]string
+	index       int
+}
+
+func newParquetReader(r io.Reader) *parquetReader {
+
+	return &parquetReader{
+		r: r,
+	}
+}
+
+func (r *parquetReader) Read() ([]string, error) {
+
+	if r.pr == nil {
+		if err := r.open(); err != nil {
+			return nil, err
+		}
+		return r.columnNames, nil
+	}
+
+	if r.index >= len(r.records) {
+		if r.remaining == 0 {
+			r.pr.ReadStop()
+			return nil, io.EOF
+		}
+		if err := r.readRows(); err != nil {
+			return nil, err
+		}
+	}
+
+	record := r.records[r.index]
+	r.index++
+	return record, nil
+}
+
+func (r *parquetReader) open() error {
+
+	file, err := newParquetSource(r.r)
+	if err != nil {
+		return err
+	}
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return errors.Wrap(err, "invalid parquet file")
+	}
+
+	handler := pr.SchemaHandler
+	root := handler.GetRootExName() + common.PAR_GO_PATH_DELIMITER
+
+	columnNames := []string{}
+	elements := []*parquet.SchemaElement{}
+	for _, path := range handler.ValueColumns {
+
+		// ネストしたカラムはドット区切りの名前に
+		name := strings.TrimPrefix(handler.InPathToExPath[path], root)
+		name = strings.ReplaceAll(name, common.PAR_GO_PATH_DELIMITER, ".")
+
+		maxRepetitionLevel, err := handler.MaxRepetitionLevel(common.StrToPath(path))
+		if err != nil {
+			return err
+		}
+		if maxRepetitionLevel > 0 {
+			return fmt.Errorf("repeated column %s is not supported", name)
+		}
+
+		columnNames = append(columnNames, name)
+		elements = append(elements, handler.SchemaElements[handler.MapIndex[path]])
+	}
+
+	r.pr = pr
+	r.columnNames = columnNames
+	r.elements = elements
+	r.remaining = pr.GetNumRows()
+	return nil
+}
+
+// カラムごとに読み込んで、行に組み立てる
+func (r *parquetReader) readRows() error {
+
+	num := r.remaining
+	if num > parquetReadRows {
+		num = parquetReadRows
+	}
+
+	records := make([][]string, num)
+	for i := range records {
+		records[i] = make([]string, len(r.columnNames))
+	}
+
+	for columnIndex, element := range r.elements {
+		values, _, _, err := r.pr.ReadColumnByIndex(int64(columnIndex), num)
+		if err != nil {
+			return err
+		}
+		if int64(len(values)) != num {
+			return fmt.Errorf("failed to read the column %s", r.columnNames[columnIndex])
+		}
+
+		for i, value := range values {
+			records[i][columnIndex] = parquetValueString(value, element)
+		}
+	}
+
+	r.remaining -= num
+	r.records = records
+	r.index = 0
+	return nil
+}
+
+// 論理型を考慮して値を文字列に
+func parquetValueString(value interface{}, element *parquet.SchemaElement) string {
+
+	if value == nil {
+		return ""
+	}
+
+	if element.IsSetConvertedType() {
+		switch element.GetConvertedType() {
+		case parquet.ConvertedType_DATE:
+			return time.Unix(int64(value.(int32))*24*60*60, 0).UTC().Format(DefaultDateLayout)
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return types.TIMESTAMP_MILLISToTime(value.(int64), true).Format(time.RFC3339Nano)
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			return types.TIMESTAMP_MICROSToTime(value.(int64), true).Format(time.RFC3339Nano)
+		case parquet.ConvertedType_DECIMAL:
+			precision, scale := int(element.GetPrecision()), int(element.GetScale())
+			switch v := value.(type) {
+			case int32:
+				return types.DECIMAL_INT_ToString(int64(v), precision, scale)
+			case int64:
+				return types.DECIMAL_INT_ToString(v, precision, scale)
+			case string:
+				return types.DECIMAL_BYTE_ARRAY_ToString([]byte(v), precision, scale)
+			}
+		}
+	}
+
+	if element.GetType() == parquet.Type_INT96 {
+		return types.INT96ToTime(value.(string)).Format(time.RFC3339Nano)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// レコードをParquetのファイルとして書き込むWriter
+// カラムの型はスキーマの指定、もしくは全ての行から推定 (どちらも無い場合は全て文字列)
+type parquetWriter struct {
+	w           io.Writer
+	schema      *Schema
+	inferTypes  bool
+	columnNames []string
+	columns     []*ColumnSchema
+	pw          *writer.ParquetWriter
+	pending     [][]string
+}
+
+func newParquetWriter(w io.Writer, f Format) *parquetWriter {
+
+	return &parquetWriter{
+		w:          w,
+		schema:     f.Schema,
+		inferTypes: f.InferTypes,
+	}
+}
+
+func (w *parquetWriter) Write(record []string) error {
+
+	if w.columnNames == nil {
+		// 同じ名前のカラムは扱えないので一意に
+		w.columnNames = DedupeColumnNames(record)
+
+		if w.schema != nil || !w.inferTypes {
+			return w.open()
+		}
+		return nil
+	}
+
+	if w.pw == nil {
+		// 途中の行で型が合わなくならないように、全ての行を読んでから型を推定する
+		w.pending = append(w.pending, record)
+		return nil
+	}
+
+	return w.write(record)
+}
+
+func (w *parquetWriter) Flush() error {
+
+	if w.columnNames == nil {
+		// ヘッダも無い場合は何も出力しない
+		return nil
+	}
+
+	if w.pw == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+
+	return w.pw.WriteStop()
+}
+
+func (w *parquetWriter) open() error {
+
+	columns, err := w.resolveColumns()
+	if err != nil {
+		return err
+	}
+	w.columns = columns
+
+	elements := []*parquet.SchemaElement{parquetRootElement(len(columns))}
+	for i, column := range columns {
+		elements = append(elements, parquetSchemaElement(w.columnNames[i], column))
+	}
+
+	pw, err := newParquetFileWriter(w.w, elements)
+	if err != nil {
+		return err
+	}
+	w.pw = pw
+
+	pending := w.pending
+	w.pending = nil
+	for _, record := range pending {
+		if err := w.write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *parquetWriter) resolveColumns() ([]*ColumnSchema, error) {
+
+	columns := []*ColumnSchema{}
+
+	if w.schema != nil {
+		for _, name := range w.columnNames {
+			column := &ColumnSchema{Name: name, Type: TypeString}
+			for _, c := range w.schema.Columns {
+				if c.Name == name {
+					column = c
+					break
+				}
+			}
+			columns = append(columns, column)
+		}
+		return columns, nil
+	}
+
+	if w.inferTypes {
+		inferred, err := InferSchema(&recordsReader{records: append([][]string{w.columnNames}, w.pending...)}, 0)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if inferred != nil {
+			return inferred.Columns, nil
+		}
+	}
+
+	for _, name := range w.columnNames {
+		columns = append(columns, &ColumnSchema{Name: name, Type: TypeString})
+	}
+	return columns, nil
+}
+
+func (w *parquetWriter) write(record []string) error {
+
+	values := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		if i >= len(record) || record[i] == "" {
+			continue
+		}
+
+		value, err := parquetValue(record[i], column)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	return w.pw.Write(values)
+}
+
+func parquetRootElement(numChildren int) *parquet.SchemaElement {
+
+	element := parquet.NewSchemaElement()
+	element.Name = "schema"
+	n := int32(numChildren)
+	element.NumChildren = &n
+	repetitionType := parquet.FieldRepetitionType_REQUIRED
+	element.RepetitionType = &repetitionType
+
+	return element
+}
+
+// スキーマの型をParquetの型に対応付け (値が無い場合があるので全てOPTIONAL)
+func parquetSchemaElement(name string, column *ColumnSchema) *parquet.SchemaElement {
+
+	element := parquet.NewSchemaElement()
+	element.Name = name
+	repetitionType := parquet.FieldRepetitionType_OPTIONAL
+	element.RepetitionType = &repetitionType
+
+	var parquetType parquet.Type
+	var convertedType *parquet.ConvertedType
+
+	switch column.Type {
+	case TypeInteger:
+		parquetType = parquet.Type_INT64
+	case TypeDecimal:
+		parquetType = parquet.Type_DOUBLE
+	case TypeBoolean:
+		parquetType = parquet.Type_BOOLEAN
+	case TypeDate:
+		if layoutHasTime(column.Layout) {
+			// 時刻を含む場合はTIMESTAMP (UTC)
+			parquetType = parquet.Type_INT64
+			t := parquet.ConvertedType_TIMESTAMP_MICROS
+			convertedType = &t
+		} else {
+			parquetType = parquet.Type_INT32
+			t := parquet.ConvertedType_DATE
+			convertedType = &t
+		}
+	default:
+		parquetType = parquet.Type_BYTE_ARRAY
+		t := parquet.ConvertedType_UTF8
+		convertedType = &t
+	}
+
+	element.Type = &parquetType
+	element.ConvertedType = convertedType
+
+	return element
+}
+
+func parquetValue(value string, column *ColumnSchema) (interface{}, error) {
+
+	switch column.Type {
+	case TypeInteger:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %s of the column %s cannot be written as %s", value, column.Name, column.Type)
+		}
+		return number, nil
+	case TypeDecimal:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %s of the column %s cannot be written as %s", value, column.Name, column.Type)
+		}
+		return number, nil
+	case TypeBoolean:
+		if !isBoolean(value) {
+			return nil, fmt.Errorf("value %s of the column %s cannot be written as %s", value, column.Name, column.Type)
+		}
+		return strings.EqualFold(value, "true"), nil
+	case TypeDate:
+		layout := column.Layout
+		if layout == "" {
+			layout = DefaultDateLayout
+		}
+		date, err := time.Parse(layout, value)
+		if err != nil {
+			return nil, fmt.Errorf("value %s of the column %s cannot be written as %s", value, column.Name, column.Type)
+		}
+		if layoutHasTime(layout) {
+			// 1970-01-01T00:00:00Zからのマイクロ秒
+			return date.UnixMicro(), nil
+		}
+		// 1970-01-01からの日数
+		return int32(date.Unix() / (24 * 60 * 60)), nil
+	}
+
+	return value, nil
+}
+
+func newParquetFileWriter(w io.Writer, elements []*parquet.SchemaElement) (*writer.ParquetWriter, error) {
+
+	handler := schema.NewSchemaHandlerFromSchemaList(elements)
+
+	pw := new(writer.ParquetWriter)
+	pw.SchemaHandler = handler
+	pw.PFile = writerfile.NewWriterFile(w)
+	pw.PageSize = 8 * 1024
+	pw.RowGroupSize = parquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	pw.PagesMapBuf = make(map[string][]*layout.Page)
+	pw.DictRecs = make(map[string]*layout.DictRecType)
+	pw.NP = 1
+	pw.Footer = parquet.NewFileMetaData()
+	pw.Footer.Version = 1
+	pw.Footer.Schema = append(pw.Footer.Schema, handler.SchemaElements...)
+	pw.Offset = 4
+	pw.MarshalFunc = marshal.MarshalCSV
+
+	if _, err := pw.PFile.Write([]byte("PAR1")); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// 読み込み済みのレコードを返すReader
+type recordsReader struct {
+	records [][]string
+	index   int
+}
+
+func (r *recordsReader) Read() ([]string, error) {
+
+	if r.index >= len(r.records) {
+		return nil, io.EOF
+	}
+
+	record := r.records[r.index]
+	r.index++
+	return record, nil
+}
+
+// Parquetのファイルは末尾のメタデータから読むので、ランダムアクセスできるものとして扱う
+type parquetSource struct {
+	r      io.ReaderAt
+	size   int64
+	offset int64
+}
+
+func newParquetSource(r io.Reader) (*parquetSource, error) {
+
+	if ra, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		size, err := ra.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		return &parquetSource{r: ra, size: size}, nil
+	}
+
+	// ランダムアクセスできない場合は全て読み込む
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetSource{r: bytes.NewReader(data), size: int64(len(data))}, nil
+}
+
+func (s *parquetSource) Read(p []byte) (int, error) {
+
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+
+	n, err := s.r.ReadAt(p, s.offset)
+	s.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (s *parquetSource) Seek(offset int64, whence int) (int64, error) {
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	}
+
+	if offset < 0 {
+		return 0, fmt.Errorf("invalid offset %d", offset)
+	}
+	s.offset = offset
+	return offset, nil
+}
+
+func (s *parquetSource) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("parquet file is opened for reading")
+}
+
+func (s *parquetSource) Close() error {
+	return nil
+}
+
+// カラムごとに読み込む位置が異なるので、別のオフセットを持つものを返す
+func (s *parquetSource) Open(name string) (source.ParquetFile, error) {
+	return &parquetSource{r: s.r, size: s.size}, nil
+}
+
+func (s *parquetSource) Create(name string) (source.ParquetFile, error) {
+	return nil, fmt.Errorf("parquet file is opened for reading")
+}
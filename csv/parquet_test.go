@@ -0,0 +1,211 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeParquet(t *testing.T, f Format, records [][]string) []byte {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatParquet, InferTypes: f.InferTypes, Schema: f.Schema})
+
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	return b.Bytes()
+}
+
+func readParquet(t *testing.T, data []byte) ([][]string, *parquetReader) {
+
+	r := NewCsvReader(bytes.NewReader(data), Format{InputFormat: InputFormatParquet})
+
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, r.(*parquetReader)
+}
+
+func TestParquetWriterReader(t *testing.T) {
+
+	records := [][]string{
+		{"ID", "Name", "Price"},
+		{"1", "Yamada", "10.5"},
+		{"2", "", "007"},
+	}
+
+	data := writeParquet(t, Format{}, records)
+	result, r := readParquet(t, data)
+
+	// 型の指定が無い場合は全て文字列 (空は null として書き込まれ、空文字として読まれる)
+	if !reflect.DeepEqual(result, records) {
+		t.Fatal("failed test\n", result)
+	}
+	for _, element := range r.elements {
+		if element.GetType().String() != "BYTE_ARRAY" {
+			t.Fatal("failed test\n", element)
+		}
+	}
+}
+
+func TestParquetWriterReader_inferTypes(t *testing.T) {
+
+	records := [][]string{
+		{"ID", "Price", "Active", "Date", "Name"},
+		{"1", "10.5", "true", "2023-01-02", "a"},
+		{"2", "", "FALSE", "1969-12-31", "b"},
+	}
+
+	data := writeParquet(t, Format{InferTypes: true}, records)
+	result, r := readParquet(t, data)
+
+	expect := [][]string{
+		{"ID", "Price", "Active", "Date", "Name"},
+		{"1", "10.5", "true", "2023-01-02", "a"},
+		{"2", "", "false", "1969-12-31", "b"},
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+
+	types := []string{}
+	for _, element := range r.elements {
+		types = append(types, element.GetType().String())
+	}
+	if !reflect.DeepEqual(types, []string{"INT64", "DOUBLE", "BOOLEAN", "INT32", "BYTE_ARRAY"}) {
+		t.Fatal("failed test\n", types)
+	}
+}
+
+func TestParquetWriterReader_inferTypesDatetime(t *testing.T) {
+
+	records := [][]string{
+		{"Time", "Code"},
+		{"2024-01-02 10:30:00", "00123"},
+		{"2024-01-03 23:59:59", "00456"},
+	}
+
+	data := writeParquet(t, Format{InferTypes: true}, records)
+	result, r := readParquet(t, data)
+
+	// 時刻を含む日付はTIMESTAMP、先頭が0の数値は文字列
+	expect := [][]string{
+		{"Time", "Code"},
+		{"2024-01-02T10:30:00Z", "00123"},
+		{"2024-01-03T23:59:59Z", "00456"},
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+	if r.elements[0].GetConvertedType().String() != "TIMESTAMP_MICROS" || r.elements[1].GetConvertedType().String() != "UTF8" {
+		t.Fatal("failed test\n", r.elements)
+	}
+}
+
+func TestParquetWriterReader_inferTypesLaterRow(t *testing.T) {
+
+	// 先頭から離れた行に型が合わない値があっても、全ての行から推定する
+	records := [][]string{{"ID", "Name"}}
+	for i := 0; i < 1500; i++ {
+		records = append(records, []string{strconv.Itoa(i), "name"})
+	}
+	records = append(records, []string{"x", "name"})
+
+	data := writeParquet(t, Format{InferTypes: true}, records)
+	result, r := readParquet(t, data)
+
+	if !reflect.DeepEqual(result, records) {
+		t.Fatal("failed test\n", len(result))
+	}
+	if r.elements[0].GetType().String() != "BYTE_ARRAY" {
+		t.Fatal("failed test\n", r.elements[0])
+	}
+}
+
+func TestParquetWriterReader_schema(t *testing.T) {
+
+	schema := &Schema{
+		Columns: []*ColumnSchema{
+			{Name: "ID", Type: TypeString},
+			{Name: "Date", Type: TypeDate, Layout: "2006/01/02"},
+		},
+	}
+
+	records := [][]string{
+		{"ID", "Date", "Other"},
+		{"001", "2023/01/02", "x"},
+	}
+
+	data := writeParquet(t, Format{Schema: schema}, records)
+	result, _ := readParquet(t, data)
+
+	expect := [][]string{
+		{"ID", "Date", "Other"},
+		{"001", "2023-01-02", "x"},
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestParquetWriter_invalidValue(t *testing.T) {
+
+	schema := &Schema{
+		Columns: []*ColumnSchema{
+			{Name: "ID", Type: TypeInteger},
+		},
+	}
+
+	w := NewCsvWriter(&bytes.Buffer{}, Format{OutputFormat: OutputFormatParquet, Schema: schema})
+	w.Write([]string{"ID"})
+
+	err := w.Write([]string{"x"})
+	if err == nil || err.Error() != "value x of the column ID cannot be written as integer" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestParquetWriterReader_manyRows(t *testing.T) {
+
+	// 読み込み単位の行数を超える
+	records := [][]string{{"ID", "Name"}}
+	for i := 0; i < 2500; i++ {
+		records = append(records, []string{strings.Repeat("1", i%10+1), "name"})
+	}
+
+	data := writeParquet(t, Format{InferTypes: true}, records)
+	result, _ := readParquet(t, data)
+
+	if !reflect.DeepEqual(result, records) {
+		t.Fatal("failed test\n", len(result))
+	}
+}
+
+func TestParquetReader_invalid(t *testing.T) {
+
+	r := NewCsvReader(strings.NewReader("ID,Name\n1,Yamada\n"), Format{InputFormat: InputFormatParquet})
+
+	_, err := r.Read()
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid parquet file") {
+		t.Fatal("failed test\n", err)
+	}
+}
@@ -0,0 +1,286 @@
+package csv
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// 表として出力する形式
+const (
+	OutputFormatMarkdown = "markdown"
+	OutputFormatHtml     = "html"
+	OutputFormatText     = "text"
+)
+
+// 切り詰めた場合の末尾
+const truncateTail = "..."
+
+// 表の形式で出力するWriter
+// 列の幅を揃えるため、ページ単位(指定が無い場合は全体)でためてから出力する
+type tableWriter struct {
+	w          *bufio.Writer
+	format     string
+	maxWidth   int
+	pageSize   int
+	noHeader   bool
+	header     []string
+	rows       [][]string
+	pageCount  int
+	htmlOpened bool
+}
+
+func newTableWriter(w io.Writer, f Format) *tableWriter {
+
+	return &tableWriter{
+		w:        bufio.NewWriter(w),
+		format:   f.OutputFormat,
+		maxWidth: f.TableMaxWidth,
+		pageSize: f.TablePageSize,
+		noHeader: f.NoOutputHeader,
+	}
+}
+
+func (w *tableWriter) Write(record []string) error {
+
+	cells := []string{}
+	for _, value := range record {
+		cells = append(cells, w.cell(value))
+	}
+
+	if w.header == nil {
+		w.header = cells
+		return nil
+	}
+
+	if w.format == OutputFormatHtml {
+		// HTMLは幅を揃える必要が無いので、そのまま出力
+		return w.writeHtmlRow(cells)
+	}
+
+	w.rows = append(w.rows, cells)
+	if w.pageSize > 0 && len(w.rows) >= w.pageSize {
+		return w.writePage()
+	}
+
+	return nil
+}
+
+func (w *tableWriter) Flush() error {
+
+	if w.header != nil {
+		var err error
+		if w.format == OutputFormatHtml {
+			err = w.closeHtml()
+		} else if len(w.rows) > 0 || w.pageCount == 0 {
+			err = w.writePage()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.w.Flush()
+}
+
+// セルの値を1行にして、最大幅で切り詰める
+func (w *tableWriter) cell(value string) string {
+
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	if w.format == OutputFormatText {
+		value = strings.ReplaceAll(value, "\n", " ")
+	}
+
+	if w.maxWidth > 0 && runewidth.StringWidth(value) > w.maxWidth {
+		value = runewidth.Truncate(value, w.maxWidth, truncateTail)
+	}
+
+	switch w.format {
+	case OutputFormatMarkdown:
+		value = strings.ReplaceAll(value, "|", "\\|")
+		value = strings.ReplaceAll(value, "\n", "<br>")
+	case OutputFormatHtml:
+		value = html.EscapeString(value)
+		value = strings.ReplaceAll(value, "\n", "<br>")
+	}
+
+	return value
+}
+
+func (w *tableWriter) writePage() error {
+
+	if w.pageCount > 0 {
+		// ページの間は空行で区切る
+		if _, err := w.w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	w.pageCount++
+
+	rows := w.rows
+	if !w.noHeader {
+		rows = append([][]string{w.header}, rows...)
+	}
+
+	widths := make([]int, len(w.header))
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := runewidth.StringWidth(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	var err error
+	if w.format == OutputFormatMarkdown {
+		err = w.writeMarkdownPage(widths)
+	} else {
+		err = w.writeTextPage(widths)
+	}
+
+	w.rows = nil
+	return err
+}
+
+func (w *tableWriter) writeMarkdownPage(widths []int) error {
+
+	for i := range widths {
+		// 区切り行は3文字以上必要
+		if widths[i] < 3 {
+			widths[i] = 3
+		}
+	}
+
+	writeRow := func(cells []string) error {
+		line := "|"
+		for i, width := range widths {
+			line += " " + padCell(cellAt(cells, i), width) + " |"
+		}
+		_, err := w.w.WriteString(line + "\n")
+		return err
+	}
+
+	// Markdownの表はヘッダの行が必須なので、ヘッダを出力しない場合は空のヘッダに
+	header := w.header
+	if w.noHeader {
+		header = nil
+	}
+	if err := writeRow(header); err != nil {
+		return err
+	}
+
+	separator := []string{}
+	for _, width := range widths {
+		separator = append(separator, strings.Repeat("-", width))
+	}
+	if err := writeRow(separator); err != nil {
+		return err
+	}
+
+	for _, row := range w.rows {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *tableWriter) writeTextPage(widths []int) error {
+
+	writeRow := func(cells []string) error {
+		values := []string{}
+		for i, width := range widths {
+			values = append(values, padCell(cellAt(cells, i), width))
+		}
+		// 末尾の空白は不要
+		_, err := w.w.WriteString(strings.TrimRight(strings.Join(values, "  "), " ") + "\n")
+		return err
+	}
+
+	if !w.noHeader {
+		if err := writeRow(w.header); err != nil {
+			return err
+		}
+
+		separator := []string{}
+		for _, width := range widths {
+			separator = append(separator, strings.Repeat("-", width))
+		}
+		if err := writeRow(separator); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range w.rows {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *tableWriter) writeHtmlRow(cells []string) error {
+
+	if !w.htmlOpened {
+		if err := w.openHtml(); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.w.WriteString("<tr>" + joinHtmlCells("td", cells) + "</tr>\n")
+	return err
+}
+
+func (w *tableWriter) openHtml() error {
+
+	w.htmlOpened = true
+	if w.noHeader {
+		_, err := w.w.WriteString("<table>\n<tbody>\n")
+		return err
+	}
+	_, err := w.w.WriteString("<table>\n<thead>\n<tr>" + joinHtmlCells("th", w.header) + "</tr>\n</thead>\n<tbody>\n")
+	return err
+}
+
+func (w *tableWriter) closeHtml() error {
+
+	if !w.htmlOpened {
+		if err := w.openHtml(); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.w.WriteString("</tbody>\n</table>\n")
+	return err
+}
+
+func joinHtmlCells(tag string, cells []string) string {
+
+	b := strings.Builder{}
+	for _, cell := range cells {
+		b.WriteString("<" + tag + ">" + cell + "</" + tag + ">")
+	}
+	return b.String()
+}
+
+func cellAt(cells []string, index int) string {
+
+	if index < len(cells) {
+		return cells[index]
+	}
+	return ""
+}
+
+// 表示幅(全角は2)で右を埋める
+func padCell(value string, width int) string {
+
+	return value + strings.Repeat(" ", width-runewidth.StringWidth(value))
+}
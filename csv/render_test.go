@@ -0,0 +1,159 @@
+package csv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func writeTable(t *testing.T, f Format, records [][]string) string {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, f)
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	return b.String()
+}
+
+func TestTableWriter_markdown(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatMarkdown}, [][]string{
+		{"ID", "Name"},
+		{"1", "山田"},
+		{"2", "a|b"},
+		{"3", "x\ny"},
+	})
+
+	expect := "| ID  | Name   |\n" +
+		"| --- | ------ |\n" +
+		"| 1   | 山田   |\n" +
+		"| 2   | a\\|b   |\n" +
+		"| 3   | x<br>y |\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_text(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatText}, [][]string{
+		{"ID", "Name", "Note"},
+		{"1", "山田", ""},
+		{"10", "Ichikawa", "x\ny"},
+	})
+
+	expect := "ID  Name      Note\n" +
+		"--  --------  ----\n" +
+		"1   山田\n" +
+		"10  Ichikawa  x y\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_html(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatHtml}, [][]string{
+		{"ID", "<Name>"},
+		{"1", "a&b"},
+		{"2", "x\ny"},
+	})
+
+	expect := "<table>\n<thead>\n<tr><th>ID</th><th>&lt;Name&gt;</th></tr>\n</thead>\n<tbody>\n" +
+		"<tr><td>1</td><td>a&amp;b</td></tr>\n" +
+		"<tr><td>2</td><td>x<br>y</td></tr>\n" +
+		"</tbody>\n</table>\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_headerOnly(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatText}, [][]string{
+		{"ID", "Name"},
+	})
+
+	if result != "ID  Name\n--  ----\n" {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_noOutputHeader(t *testing.T) {
+
+	records := [][]string{
+		{"ID", "Name"},
+		{"1", "Yamada"},
+		{"22", "a"},
+	}
+
+	// ヘッダの値は列の幅にも含めない
+	result := writeTable(t, Format{OutputFormat: OutputFormatText, NoOutputHeader: true}, records)
+	if result != "1   Yamada\n22  a\n" {
+		t.Fatal("failed test\n", result)
+	}
+
+	// Markdownはヘッダの行が必須なので空のヘッダ
+	result = writeTable(t, Format{OutputFormat: OutputFormatMarkdown, NoOutputHeader: true}, records)
+	expect := "|     |        |\n" +
+		"| --- | ------ |\n" +
+		"| 1   | Yamada |\n" +
+		"| 22  | a      |\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = writeTable(t, Format{OutputFormat: OutputFormatHtml, NoOutputHeader: true}, records)
+	expect = "<table>\n<tbody>\n" +
+		"<tr><td>1</td><td>Yamada</td></tr>\n" +
+		"<tr><td>22</td><td>a</td></tr>\n" +
+		"</tbody>\n</table>\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_maxWidth(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatText, TableMaxWidth: 6}, [][]string{
+		{"ID", "Name"},
+		{"1", "abcdefghij"},
+		{"2", "あいうえお"},
+	})
+
+	expect := "ID  Name\n" +
+		"--  ------\n" +
+		"1   abc...\n" +
+		"2   あ...\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestTableWriter_pageSize(t *testing.T) {
+
+	result := writeTable(t, Format{OutputFormat: OutputFormatText, TablePageSize: 2}, [][]string{
+		{"ID", "Name"},
+		{"1", "a"},
+		{"2", "bbbbbb"},
+		{"3", "c"},
+	})
+
+	expect := "ID  Name\n" +
+		"--  ------\n" +
+		"1   a\n" +
+		"2   bbbbbb\n" +
+		"\n" +
+		"ID  Name\n" +
+		"--  ----\n" +
+		"3   c\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
@@ -2,14 +2,12 @@ package csv
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strconv"
 
 	"github.com/boltdb/bolt"
-	"golang.org/x/exp/slices"
 )
 
 type CsvSortedRows interface {
@@ -52,15 +50,9 @@ func LoadCsvMemorySortedRows(reader CsvReader, useColumnNames []string, compare
 		return nil, err
 	}
 
-	useColumnIndexes := []int{}
-	for _, useColumnName := range useColumnNames {
-
-		useColumnIndex := slices.Index(allColumnNames, useColumnName)
-		if useColumnIndex == -1 {
-			return nil, fmt.Errorf("%s is not found", useColumnName)
-		}
-
-		useColumnIndexes = append(useColumnIndexes, useColumnIndex)
+	useColumnIndexes, err := ColumnIndexes(allColumnNames, useColumnNames)
+	if err != nil {
+		return nil, err
 	}
 
 	rows := [][]string{}
@@ -117,6 +109,7 @@ type fileSortedRows struct {
 	columnNames    []string
 	dbPath         string
 	db             *bolt.DB
+	codec          *tempValueCodec
 }
 
 func (t *fileSortedRows) Count() int {
@@ -142,7 +135,11 @@ func (t *fileSortedRows) Row(index int) ([]string, error) {
 
 		v := b.Get([]byte(strconv.Itoa(t.sortedIndexies[index])))
 		if v != nil {
-			json.Unmarshal(v, &row)
+			rowJson, err := t.codec.decode(v)
+			if err != nil {
+				return err
+			}
+			json.Unmarshal(rowJson, &row)
 		}
 
 		return nil
@@ -166,6 +163,8 @@ func (t *fileSortedRows) ColumnNames() []string {
 
 func (t *fileSortedRows) Close() error {
 
+	t.codec.Close()
+
 	if t.db != nil {
 		err := t.db.Close()
 		if err != nil {
@@ -181,22 +180,22 @@ type SortSource struct {
 	items []string
 }
 
-func LoadCsvFileSortedRows(reader CsvReader, useColumnNames []string, compare func(item1 string, item2 string) (int, error)) (CsvSortedRows, error) {
+// compressにtrueを指定した場合、一時ファイルに格納する行を圧縮する
+func LoadCsvFileSortedRows(reader CsvReader, useColumnNames []string, compare func(item1 string, item2 string) (int, error), compress bool) (CsvSortedRows, error) {
 
 	allColumnNames, err := reader.Read()
 	if err != nil {
 		return nil, err
 	}
 
-	useColumnIndexes := []int{}
-	for _, useColumnName := range useColumnNames {
-
-		useColumnIndex := slices.Index(allColumnNames, useColumnName)
-		if useColumnIndex == -1 {
-			return nil, fmt.Errorf("%s is not found", useColumnName)
-		}
+	useColumnIndexes, err := ColumnIndexes(allColumnNames, useColumnNames)
+	if err != nil {
+		return nil, err
+	}
 
-		useColumnIndexes = append(useColumnIndexes, useColumnIndex)
+	codec, err := newTempValueCodec(compress)
+	if err != nil {
+		return nil, err
 	}
 
 	dbFile, err := os.CreateTemp("", "csvdb")
@@ -250,7 +249,7 @@ func LoadCsvFileSortedRows(reader CsvReader, useColumnNames []string, compare fu
 					return err
 				}
 
-				err = b.Put([]byte(strconv.Itoa(rowIndex)), []byte(rowJson))
+				err = b.Put([]byte(strconv.Itoa(rowIndex)), codec.encode(rowJson))
 				if err != nil {
 					return err
 				}
@@ -305,6 +304,7 @@ func LoadCsvFileSortedRows(reader CsvReader, useColumnNames []string, compare fu
 		sortedIndexies: sortedIndexies,
 		columnNames:    allColumnNames,
 		dbPath:         dbFile.Name(),
+		codec:          codec,
 	}, nil
 }
 
@@ -265,7 +265,7 @@ func TestLoadCsvFileSortedRows(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString)
+	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString, false)
 
 	if err != nil {
 		t.Fatal("failed test\n", err)
@@ -289,6 +289,31 @@ func TestLoadCsvFileSortedRows(t *testing.T) {
 	)
 }
 
+func TestLoadCsvFileSortedRows_compress(t *testing.T) {
+
+	s := joinRows(
+		[]string{"col1", "col2"},
+		[]string{"2", "b"},
+		[]string{"1", "あいう"},
+		[]string{"3", ""},
+	)
+
+	r := NewCsvReader(strings.NewReader(s), Format{})
+
+	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString, true)
+
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	defer rows.Close()
+
+	assertRows(t, rows,
+		[]string{"1", "あいう"},
+		[]string{"2", "b"},
+		[]string{"3", ""},
+	)
+}
+
 func TestLoadCsvFileSortedRows_multiColumn(t *testing.T) {
 
 	s := joinRows(
@@ -302,7 +327,7 @@ func TestLoadCsvFileSortedRows_multiColumn(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	rows, err := LoadCsvFileSortedRows(r, []string{"col1", "col2"}, CompareString)
+	rows, err := LoadCsvFileSortedRows(r, []string{"col1", "col2"}, CompareString, false)
 
 	if err != nil {
 		t.Fatal("failed test\n", err)
@@ -338,7 +363,7 @@ func TestLoadCsvFileSortedRows_num(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareNumber)
+	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareNumber, false)
 
 	if err != nil {
 		t.Fatal("failed test\n", err)
@@ -375,7 +400,7 @@ func TestLoadCsvFileSortedRows_same(t *testing.T) {
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
 	// col1だけ指定して同じ値がどうなるか確認
-	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString)
+	rows, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString, false)
 
 	if err != nil {
 		t.Fatal("failed test\n", err)
@@ -403,7 +428,7 @@ func TestLoadCsvFileSortedRows_empty(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(""), Format{})
 
-	_, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString)
+	_, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareString, false)
 
 	if err != io.EOF {
 		t.Fatal("failed test\n", err)
@@ -419,7 +444,7 @@ func TestLoadCsvFileSortedRows_columnNotFound(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileSortedRows(r, []string{"col1", "col3"}, CompareString)
+	_, err := LoadCsvFileSortedRows(r, []string{"col1", "col3"}, CompareString, false)
 
 	if err == nil || err.Error() != "col3 is not found" {
 		t.Fatal("failed test\n", err)
@@ -437,7 +462,7 @@ func TestLoadCsvFileSortedRows_invalidNumber(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareNumber)
+	_, err := LoadCsvFileSortedRows(r, []string{"col1"}, CompareNumber, false)
 
 	if err == nil || err.Error() != `strconv.Atoi: parsing "a": invalid syntax` {
 		t.Fatal("failed test\n", err)
@@ -456,7 +481,7 @@ func TestLoadCsvFileSortedRows_big(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(strings.Join(s[:], "\n")), Format{})
 
-	rows, err := LoadCsvFileSortedRows(r, []string{"col2"}, CompareString)
+	rows, err := LoadCsvFileSortedRows(r, []string{"col2"}, CompareString, false)
 
 	if err != nil {
 		t.Fatal("failed test\n", err)
@@ -0,0 +1,183 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/onozaty/go-customcsv"
+	"github.com/pkg/errors"
+)
+
+type RecordError struct {
+	Record  int
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *RecordError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("parse error on record %d (line %d): %s", e.Record, e.Line, e.Message)
+	} else {
+		return fmt.Sprintf("parse error on record %d (line %d), column %d: %s", e.Record, e.Line, e.Column, e.Message)
+	}
+}
+
+// 不正なレコードがあっても、読み込みを継続できるReader
+type RecordScanner struct {
+	r              *customcsv.Reader
+	numFields      int
+	numRecord      int
+	startLine      int
+	line           int
+	separatorLines int
+}
+
+func NewRecordScanner(r io.Reader, f Format) *RecordScanner {
+
+	cr := newCustomReader(r, f)
+	// 項目数はこちらで確認する
+	cr.FieldsPerRecord = -1
+
+	// 行番号はレコード区切り文字と項目内の改行から算出
+	separatorLines := 1
+	if f.RecordSeparator != "" {
+		separatorLines = strings.Count(f.RecordSeparator, "\n")
+	}
+
+	return &RecordScanner{
+		r:              cr,
+		line:           1,
+		separatorLines: separatorLines,
+	}
+}
+
+// 不正なレコードの場合はRecordErrorを返す
+// 項目数の不一致の場合には、読み込んだレコードも合わせて返す
+func (s *RecordScanner) Scan() ([]string, *RecordError, error) {
+
+	s.numRecord++
+	s.startLine = s.line
+	startLine := s.line
+
+	record, err := s.r.Read()
+	if err == io.EOF {
+		return nil, nil, err
+	}
+
+	var parseErr *customcsv.ParseError
+	if errors.As(err, &parseErr) {
+		// クォートの不整合は、レコードの途中で読み込みが止まっているので
+		// 次のレコード区切りまでを読み捨てる
+		if err := s.discardRemaining(); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, &RecordError{
+			Record:  s.numRecord,
+			Line:    startLine,
+			Column:  parseErr.Column,
+			Message: parseErr.Message,
+		}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.line += s.countLines(record)
+
+	if s.numFields == 0 {
+		// 先頭レコードの項目数を基準とする
+		s.numFields = len(record)
+	} else if len(record) != s.numFields {
+		return record, &RecordError{
+			Record:  s.numRecord,
+			Line:    startLine,
+			Message: fmt.Sprintf("wrong number of fields (expected %d, actual %d)", s.numFields, len(record)),
+		}, nil
+	}
+
+	return record, nil, nil
+}
+
+// 直前に読み込んだレコードの番号と開始行
+func (s *RecordScanner) Position() (int, int) {
+	return s.numRecord, s.startLine
+}
+
+func (s *RecordScanner) NumFields() int {
+	return s.numFields
+}
+
+func (s *RecordScanner) discardRemaining() error {
+
+	for {
+		remaining, err := s.r.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *customcsv.ParseError
+		if errors.As(err, &parseErr) {
+			// 残りの部分にも不整合があった場合は、さらに読み捨てる
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		s.line += s.countLines(remaining)
+		return nil
+	}
+}
+
+func (s *RecordScanner) countLines(record []string) int {
+
+	lines := s.separatorLines
+	for _, field := range record {
+		lines += strings.Count(field, "\n")
+	}
+	return lines
+}
+
+// 不正なレコードを指定された方法で扱うReader
+type errorPolicyReader struct {
+	scanner *RecordScanner
+	onError string
+	log     io.Writer
+}
+
+func (r *errorPolicyReader) Read() ([]string, error) {
+
+	for {
+		record, recordErr, err := r.scanner.Scan()
+		if err != nil {
+			return nil, err
+		}
+		if recordErr == nil {
+			return record, nil
+		}
+
+		switch r.onError {
+		case OnErrorPad:
+			// 項目数が足りない場合のみ空の項目で補う
+			if record == nil || len(record) > r.scanner.NumFields() {
+				return nil, recordErr
+			}
+			for len(record) < r.scanner.NumFields() {
+				record = append(record, "")
+			}
+			return record, nil
+
+		case OnErrorLog:
+			if r.log != nil {
+				if _, err := fmt.Fprintln(r.log, recordErr.Error()); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// skip, logの場合は次のレコードへ
+	}
+}
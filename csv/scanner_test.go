@@ -0,0 +1,146 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordScanner(t *testing.T) {
+
+	s := "ID,Name\n1,\"Yamada\nTaro\"\n2\n3,Sa\"to\n4,Suzuki,x\n5,Tanaka\n"
+
+	scanner := NewRecordScanner(strings.NewReader(s), Format{})
+
+	expects := []struct {
+		record []string
+		err    *RecordError
+	}{
+		{[]string{"ID", "Name"}, nil},
+		{[]string{"1", "Yamada\nTaro"}, nil},
+		{[]string{"2"}, &RecordError{Record: 3, Line: 4, Message: "wrong number of fields (expected 2, actual 1)"}},
+		{nil, &RecordError{Record: 4, Line: 5, Column: 2, Message: "bare quote in non quoted field"}},
+		{[]string{"4", "Suzuki", "x"}, &RecordError{Record: 5, Line: 6, Message: "wrong number of fields (expected 2, actual 3)"}},
+		{[]string{"5", "Tanaka"}, nil},
+	}
+
+	for _, expect := range expects {
+		record, recordErr, err := scanner.Scan()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect.record) || !reflect.DeepEqual(recordErr, expect.err) {
+			t.Fatal("failed test\n", record, recordErr)
+		}
+	}
+
+	_, _, err := scanner.Scan()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestRecordScanner_position(t *testing.T) {
+
+	s := "ID|\"a\nb\"|x|"
+
+	scanner := NewRecordScanner(strings.NewReader(s), Format{RecordSeparator: "|"})
+
+	scanner.Scan()
+	record, line := scanner.Position()
+	if record != 1 || line != 1 {
+		t.Fatal("failed test\n", record, line)
+	}
+
+	scanner.Scan()
+	record, line = scanner.Position()
+	if record != 2 || line != 1 {
+		t.Fatal("failed test\n", record, line)
+	}
+
+	// 項目内の改行は行として数える
+	scanner.Scan()
+	record, line = scanner.Position()
+	if record != 3 || line != 2 {
+		t.Fatal("failed test\n", record, line)
+	}
+}
+
+func TestNewCsvReader_onErrorSkip(t *testing.T) {
+
+	s := "ID,Name\n1,A\n2\n3,\"C\"x\n4,D\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{OnError: OnErrorSkip})
+
+	records := readAll(t, r)
+	expect := [][]string{{"ID", "Name"}, {"1", "A"}, {"4", "D"}}
+
+	if !reflect.DeepEqual(records, expect) {
+		t.Fatal("failed test\n", records)
+	}
+}
+
+func TestNewCsvReader_onErrorPad(t *testing.T) {
+
+	s := "ID,Name,Note\n1,A\n2\n3,C,x\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{OnError: OnErrorPad})
+
+	records := readAll(t, r)
+	expect := [][]string{{"ID", "Name", "Note"}, {"1", "A", ""}, {"2", "", ""}, {"3", "C", "x"}}
+
+	if !reflect.DeepEqual(records, expect) {
+		t.Fatal("failed test\n", records)
+	}
+}
+
+func TestNewCsvReader_onErrorPadTooMany(t *testing.T) {
+
+	s := "ID,Name\n1,A,x\n"
+
+	r := NewCsvReader(strings.NewReader(s), Format{OnError: OnErrorPad})
+
+	r.Read()
+	_, err := r.Read()
+	if err == nil || err.Error() != "parse error on record 2 (line 2): wrong number of fields (expected 2, actual 3)" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestNewCsvReader_onErrorLog(t *testing.T) {
+
+	s := "ID,Name\n1,A\n2\n3,C\n"
+
+	log := &strings.Builder{}
+
+	r := NewCsvReader(strings.NewReader(s), Format{OnError: OnErrorLog, OnErrorLogWriter: log})
+
+	records := readAll(t, r)
+	expect := [][]string{{"ID", "Name"}, {"1", "A"}, {"3", "C"}}
+
+	if !reflect.DeepEqual(records, expect) {
+		t.Fatal("failed test\n", records)
+	}
+
+	if log.String() != "parse error on record 3 (line 3): wrong number of fields (expected 2, actual 1)\n" {
+		t.Fatal("failed test\n", log.String())
+	}
+}
+
+func readAll(t *testing.T, r CsvReader) [][]string {
+
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -0,0 +1,206 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeDecimal = "decimal"
+	TypeBoolean = "boolean"
+	TypeDate    = "date"
+	TypeEnum    = "enum"
+	TypeRegex   = "regex"
+)
+
+const DefaultDateLayout = "2006-01-02"
+
+type Schema struct {
+	Columns []*ColumnSchema `json:"columns" yaml:"columns"`
+}
+
+type ColumnSchema struct {
+	Name      string   `json:"name" yaml:"name"`
+	Type      string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Layout    string   `json:"layout,omitempty" yaml:"layout,omitempty"`
+	Values    []string `json:"values,omitempty" yaml:"values,omitempty"`
+	Pattern   string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Required  bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	NotNull   bool     `json:"notNull,omitempty" yaml:"notNull,omitempty"`
+	Unique    bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+
+	// 推定時の参考情報 (検証には使用しない)
+	NullRatio *float64 `json:"nullRatio,omitempty" yaml:"nullRatio,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+var integerPattern = regexp.MustCompile(`^[+-]?[0-9]+$`)
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+func LoadSchema(path string) (*Schema, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+
+	// 拡張子でYAMLかJSONかを判断
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(data, schema)
+	} else {
+		err = json.Unmarshal(data, schema)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schema file %s", path)
+	}
+
+	if err := schema.Compile(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func SaveSchema(schema *Schema, path string) error {
+
+	var data []byte
+	var err error
+
+	// 読み込み時と同じく拡張子でYAMLかJSONかを判断
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		buf := new(bytes.Buffer)
+		encoder := yaml.NewEncoder(buf)
+		encoder.SetIndent(2)
+		err = encoder.Encode(schema)
+		data = buf.Bytes()
+	} else {
+		data, err = json.MarshalIndent(schema, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (s *Schema) Compile() error {
+
+	names := []string{}
+	for _, c := range s.Columns {
+
+		if c.Name == "" {
+			return fmt.Errorf("column name is not specified in the schema")
+		}
+		if slices.Contains(names, c.Name) {
+			return fmt.Errorf("column %s is duplicated in the schema", c.Name)
+		}
+		names = append(names, c.Name)
+
+		if err := c.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *ColumnSchema) compile() error {
+
+	switch c.Type {
+	case "", TypeString:
+		c.Type = TypeString
+	case "int", TypeInteger:
+		c.Type = TypeInteger
+	case TypeDecimal:
+	case "bool", TypeBoolean:
+		c.Type = TypeBoolean
+	case TypeDate:
+		if c.Layout == "" {
+			c.Layout = DefaultDateLayout
+		}
+	case TypeEnum:
+		if len(c.Values) == 0 {
+			return fmt.Errorf("values is not specified for the enum column %s", c.Name)
+		}
+	case TypeRegex:
+		regex, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "pattern of the column %s is invalid", c.Name)
+		}
+		c.regex = regex
+	default:
+		return fmt.Errorf("type %s of the column %s is invalid", c.Type, c.Name)
+	}
+
+	return nil
+}
+
+// 値がスキーマに違反している場合、違反したルール名を返す
+// (一意性はファイル全体で判断する必要があるので、ここでは確認しない)
+func (c *ColumnSchema) Validate(value string) []string {
+
+	if value == "" {
+		if c.NotNull {
+			return []string{"notNull"}
+		}
+		return nil
+	}
+
+	violations := []string{}
+
+	if !c.matchType(value) {
+		violations = append(violations, c.Type)
+	}
+
+	if c.MaxLength > 0 && utf8.RuneCountInString(value) > c.MaxLength {
+		violations = append(violations, "maxLength")
+	}
+
+	return violations
+}
+
+func (c *ColumnSchema) matchType(value string) bool {
+
+	switch c.Type {
+	case TypeInteger:
+		return integerPattern.MatchString(value)
+	case TypeDecimal:
+		return decimalPattern.MatchString(value)
+	case TypeBoolean:
+		return isBoolean(value)
+	case TypeDate:
+		_, err := time.Parse(c.Layout, value)
+		return err == nil
+	case TypeEnum:
+		return slices.Contains(c.Values, value)
+	case TypeRegex:
+		return c.regex.MatchString(value)
+	default:
+		return true
+	}
+}
+
+func isBoolean(value string) bool {
+	lower := strings.ToLower(value)
+	return lower == "true" || lower == "false"
+}
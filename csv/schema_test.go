@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSchema(t *testing.T) {
+
+	f := createTempFile(t, `{"columns": [{"name": "A"}, {"name": "B", "type": "int"}, {"name": "C", "type": "date"}]}`)
+	defer os.Remove(f)
+
+	schema, err := LoadSchema(f)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if len(schema.Columns) != 3 {
+		t.Fatal("failed test\n", schema.Columns)
+	}
+
+	// 省略時や別名が正規化されていること
+	if schema.Columns[0].Type != TypeString {
+		t.Fatal("failed test\n", schema.Columns[0].Type)
+	}
+	if schema.Columns[1].Type != TypeInteger {
+		t.Fatal("failed test\n", schema.Columns[1].Type)
+	}
+	if schema.Columns[2].Layout != DefaultDateLayout {
+		t.Fatal("failed test\n", schema.Columns[2].Layout)
+	}
+}
+
+func TestLoadSchema_duplicateColumn(t *testing.T) {
+
+	f := createTempFile(t, `{"columns": [{"name": "A"}, {"name": "A"}]}`)
+	defer os.Remove(f)
+
+	_, err := LoadSchema(f)
+	if err == nil || err.Error() != "column A is duplicated in the schema" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestLoadSchema_invalidPattern(t *testing.T) {
+
+	f := createTempFile(t, `{"columns": [{"name": "A", "type": "regex", "pattern": "["}]}`)
+	defer os.Remove(f)
+
+	_, err := LoadSchema(f)
+	if err == nil || err.Error() != "pattern of the column A is invalid: error parsing regexp: missing closing ]: `[`" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestLoadSchema_fileNotFound(t *testing.T) {
+
+	f := createTempFile(t, "")
+	defer os.Remove(f)
+
+	_, err := LoadSchema(f + "____")
+	if err == nil {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestColumnSchema_Validate(t *testing.T) {
+
+	schema := &Schema{
+		Columns: []*ColumnSchema{
+			{Name: "A", Type: "integer", NotNull: true},
+			{Name: "B", Type: "decimal", MaxLength: 3},
+			{Name: "C", Type: "enum", Values: []string{"x", "y"}},
+		},
+	}
+	if err := schema.Compile(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	tests := []struct {
+		column *ColumnSchema
+		value  string
+		expect []string
+	}{
+		{schema.Columns[0], "", []string{"notNull"}},
+		{schema.Columns[0], "-12", []string{}},
+		{schema.Columns[0], "1.2", []string{"integer"}},
+		{schema.Columns[1], "", nil},
+		{schema.Columns[1], "1.2", []string{}},
+		{schema.Columns[1], "1.234", []string{"maxLength"}},
+		{schema.Columns[1], "abcd", []string{"decimal", "maxLength"}},
+		{schema.Columns[2], "x", []string{}},
+		{schema.Columns[2], "z", []string{"enum"}},
+	}
+
+	for _, test := range tests {
+		result := test.column.Validate(test.value)
+		if !reflect.DeepEqual(result, test.expect) {
+			t.Fatal("failed test\n", test.column.Name, test.value, result)
+		}
+	}
+}
+
+func createTempFile(t *testing.T, content string) string {
+
+	tempFile, err := os.CreateTemp("", "csv")
+	if err != nil {
+		t.Fatal("craete file failed\n", err)
+	}
+
+	_, err = tempFile.Write([]byte(content))
+	if err != nil {
+		t.Fatal("write file failed\n", err)
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		t.Fatal("write file failed\n", err)
+	}
+
+	return tempFile.Name()
+}
@@ -0,0 +1,297 @@
+package csv
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+type ColumnNotFoundError struct {
+	Selector string
+}
+
+func (e *ColumnNotFoundError) Error() string {
+	return fmt.Sprintf("%s is not found", e.Selector)
+}
+
+var columnRangePattern = regexp.MustCompile(`^([0-9]+)-([0-9]+|last)$`)
+var columnOccurrencePattern = regexp.MustCompile(`^(.+)#([0-9]+)$`)
+
+// カラムの指定に一致するカラムのインデックスを返す
+// 指定は以下の形式で、カラム名と完全一致するものがあればそれを優先
+// (同じ名前のカラムが複数ある場合は、その全てに一致)
+//
+//	name#2   同じ名前のカラムのうち2つ目
+//	3        カラム番号(1始まり)
+//	2-5      カラム番号の範囲 (2-last のように末尾までも可)
+//	last     最後のカラム
+//	/^amt_/  カラム名に対する正規表現
+//	price_*  カラム名に対するglob
+func MatchColumns(columnNames []string, selector string) ([]int, error) {
+
+	if indexes := columnNameIndexes(columnNames, selector); len(indexes) != 0 {
+		return indexes, nil
+	}
+
+	if matches := columnOccurrencePattern.FindStringSubmatch(selector); matches != nil && slices.Contains(columnNames, matches[1]) {
+		n, _ := strconv.Atoi(matches[2])
+		if index := NthColumnIndex(columnNames, matches[1], n); index != -1 {
+			return []int{index}, nil
+		}
+		return []int{}, nil
+	}
+
+	if selector == "last" {
+		if len(columnNames) == 0 {
+			return []int{}, nil
+		}
+		return []int{len(columnNames) - 1}, nil
+	}
+
+	if number, err := strconv.Atoi(selector); err == nil && selector[0] != '+' && selector[0] != '-' {
+		if number < 1 || number > len(columnNames) {
+			return []int{}, nil
+		}
+		return []int{number - 1}, nil
+	}
+
+	if matches := columnRangePattern.FindStringSubmatch(selector); matches != nil {
+		start, _ := strconv.Atoi(matches[1])
+		end := len(columnNames)
+		if matches[2] != "last" {
+			end, _ = strconv.Atoi(matches[2])
+		}
+
+		if start < 1 || start > len(columnNames) || end < 1 || end > len(columnNames) {
+			return []int{}, nil
+		}
+
+		indexes := []int{}
+		if start <= end {
+			for i := start; i <= end; i++ {
+				indexes = append(indexes, i-1)
+			}
+		} else {
+			// 逆順の指定
+			for i := start; i >= end; i-- {
+				indexes = append(indexes, i-1)
+			}
+		}
+		return indexes, nil
+	}
+
+	if len(selector) >= 2 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/") {
+		regex, err := regexp.Compile(selector[1 : len(selector)-1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "regular expression of column %s is invalid", selector)
+		}
+
+		indexes := []int{}
+		for i, columnName := range columnNames {
+			if regex.MatchString(columnName) {
+				indexes = append(indexes, i)
+			}
+		}
+		return indexes, nil
+	}
+
+	if strings.ContainsAny(selector, "*?[") {
+		indexes := []int{}
+		for i, columnName := range columnNames {
+			matched, err := path.Match(selector, columnName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "pattern of column %s is invalid", selector)
+			}
+			if matched {
+				indexes = append(indexes, i)
+			}
+		}
+		return indexes, nil
+	}
+
+	return []int{}, nil
+}
+
+// 複数のカラムの指定を解決して、カラムのインデックスを返す
+// "!"で始まる指定は除外として扱い、除外の指定のみの場合は全カラムから除外する
+func ColumnIndexes(columnNames []string, selectors []string) ([]int, error) {
+
+	indexes := []int{}
+	excludeIndexes := []int{}
+	hasInclude := false
+
+	for _, selector := range selectors {
+
+		exclude := false
+		if strings.HasPrefix(selector, "!") && !slices.Contains(columnNames, selector) {
+			exclude = true
+			selector = selector[1:]
+		}
+
+		matches, err := MatchColumns(columnNames, selector)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, &ColumnNotFoundError{Selector: selector}
+		}
+
+		if exclude {
+			excludeIndexes = append(excludeIndexes, matches...)
+			continue
+		}
+
+		hasInclude = true
+		if !isColumnPattern(columnNames, selector) {
+			// カラム名や番号での指定は、繰り返し指定された場合もそのまま含める
+			indexes = append(indexes, matches...)
+			continue
+		}
+
+		// 範囲やパターンでの指定は、既に含まれるカラムを除いて追加
+		for _, index := range matches {
+			if !slices.Contains(indexes, index) {
+				indexes = append(indexes, index)
+			}
+		}
+	}
+
+	if !hasInclude && len(excludeIndexes) != 0 {
+		for i := range columnNames {
+			indexes = append(indexes, i)
+		}
+	}
+
+	result := []int{}
+	for _, index := range indexes {
+		if !slices.Contains(excludeIndexes, index) {
+			result = append(result, index)
+		}
+	}
+
+	return result, nil
+}
+
+// 1つのカラムを対象とする指定を解決して、カラムのインデックスを返す
+func ColumnIndex(columnNames []string, selector string) (int, error) {
+
+	matches, err := MatchColumns(columnNames, selector)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(matches) == 0 {
+		return -1, &ColumnNotFoundError{Selector: selector}
+	}
+	if len(matches) > 1 {
+		if slices.Contains(columnNames, selector) {
+			return -1, fmt.Errorf("%s is duplicated in the header (specify like %s#2, or use --dedupe-headers)", selector, selector)
+		}
+		return -1, fmt.Errorf("%s matches multiple columns", selector)
+	}
+
+	return matches[0], nil
+}
+
+// 同じ名前のカラムのうち、n番目(1始まり)のインデックスを返す (無い場合は-1)
+func NthColumnIndex(columnNames []string, columnName string, n int) int {
+
+	count := 0
+	for i, name := range columnNames {
+		if name == columnName {
+			count++
+			if count == n {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// 範囲、正規表現、globでの指定か
+func isColumnPattern(columnNames []string, selector string) bool {
+
+	if slices.Contains(columnNames, selector) {
+		return false
+	}
+
+	return columnRangePattern.MatchString(selector) ||
+		(len(selector) >= 2 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/")) ||
+		strings.ContainsAny(selector, "*?[")
+}
+
+func columnNameIndexes(columnNames []string, columnName string) []int {
+
+	indexes := []int{}
+	for i, name := range columnNames {
+		if name == columnName {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// ヘッダ内で重複しているカラム名を返す
+func DuplicateColumnNames(columnNames []string) []string {
+
+	counts := map[string]int{}
+	duplicates := []string{}
+	for _, name := range columnNames {
+		counts[name]++
+		if counts[name] == 2 {
+			duplicates = append(duplicates, name)
+		}
+	}
+
+	return duplicates
+}
+
+// カラム名をキーとして扱う処理向けに、重複したカラム名が無いことを確認
+func CheckDuplicateColumnNames(columnNames []string) error {
+
+	duplicates := DuplicateColumnNames(columnNames)
+	if len(duplicates) != 0 {
+		return fmt.Errorf("duplicate column names in the header: %s (use --dedupe-headers)", strings.Join(duplicates, ", "))
+	}
+
+	return nil
+}
+
+// 重複したカラム名に連番を付与して一意にする (name, name_2, name_3, ...)
+func DedupeColumnNames(columnNames []string) []string {
+
+	used := map[string]bool{}
+	for _, name := range columnNames {
+		used[name] = true
+	}
+
+	seen := map[string]int{}
+	result := []string{}
+	for _, name := range columnNames {
+		seen[name]++
+		if seen[name] == 1 {
+			result = append(result, name)
+			continue
+		}
+
+		// 既存のカラム名と衝突しないように番号を進める
+		n := seen[name]
+		newName := fmt.Sprintf("%s_%d", name, n)
+		for used[newName] {
+			n++
+			newName = fmt.Sprintf("%s_%d", name, n)
+		}
+		seen[name] = n
+		used[newName] = true
+		result = append(result, newName)
+	}
+
+	return result
+}
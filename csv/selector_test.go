@@ -0,0 +1,179 @@
+package csv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnIndexes(t *testing.T) {
+
+	columnNames := []string{"id", "name", "price_a", "price_b", "amt_x", "amt_y", "3"}
+
+	tests := []struct {
+		selectors []string
+		expect    []int
+	}{
+		{[]string{"name"}, []int{1}},
+		{[]string{"2"}, []int{1}},
+		{[]string{"3"}, []int{6}}, // カラム名が優先
+		{[]string{"2-4"}, []int{1, 2, 3}},
+		{[]string{"4-2"}, []int{3, 2, 1}},
+		{[]string{"6-last"}, []int{5, 6}},
+		{[]string{"last"}, []int{6}},
+		{[]string{"/^amt_/"}, []int{4, 5}},
+		{[]string{"price_*"}, []int{2, 3}},
+		{[]string{"price_?", "id"}, []int{2, 3, 0}},
+		{[]string{"!id"}, []int{1, 2, 3, 4, 5, 6}},
+		{[]string{"/^(amt|price)_/", "!price_b", "!amt_x"}, []int{2, 5}},
+		{[]string{"name", "name"}, []int{1, 1}}, // 名前や番号の繰り返しはそのまま
+		{[]string{"1", "id"}, []int{0, 0}},
+		{[]string{"1", "id", "1-2"}, []int{0, 0, 1}}, // 範囲やパターンでは既に含まれるものを除外
+		{[]string{"price_a", "/^price_/"}, []int{2, 3}},
+		{[]string{}, []int{}},
+	}
+
+	for _, test := range tests {
+		result, err := ColumnIndexes(columnNames, test.selectors)
+		if err != nil {
+			t.Fatal("failed test\n", test.selectors, err)
+		}
+		if !reflect.DeepEqual(result, test.expect) {
+			t.Fatal("failed test\n", test.selectors, result)
+		}
+	}
+}
+
+func TestColumnIndexes_notFound(t *testing.T) {
+
+	columnNames := []string{"id", "name"}
+
+	for _, selector := range []string{"x", "0", "3", "1-3", "/^x/", "x*", "!x"} {
+		_, err := ColumnIndexes(columnNames, []string{selector})
+		if _, ok := err.(*ColumnNotFoundError); !ok {
+			t.Fatal("failed test\n", selector, err)
+		}
+	}
+}
+
+func TestColumnIndexes_invalid(t *testing.T) {
+
+	columnNames := []string{"id", "name"}
+
+	_, err := ColumnIndexes(columnNames, []string{"/[/"})
+	if err == nil || err.Error() != "regular expression of column /[/ is invalid: error parsing regexp: missing closing ]: `[`" {
+		t.Fatal("failed test\n", err)
+	}
+
+	_, err = ColumnIndexes(columnNames, []string{"[*"})
+	if err == nil || err.Error() != "pattern of column [* is invalid: syntax error in pattern" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+
+	columnNames := []string{"id", "name", "price_a", "price_b"}
+
+	index, err := ColumnIndex(columnNames, "price_b")
+	if err != nil || index != 3 {
+		t.Fatal("failed test\n", index, err)
+	}
+
+	index, err = ColumnIndex(columnNames, "last")
+	if err != nil || index != 3 {
+		t.Fatal("failed test\n", index, err)
+	}
+
+	index, err = ColumnIndex(columnNames, "/^n/")
+	if err != nil || index != 1 {
+		t.Fatal("failed test\n", index, err)
+	}
+
+	_, err = ColumnIndex(columnNames, "price_*")
+	if err == nil || err.Error() != "price_* matches multiple columns" {
+		t.Fatal("failed test\n", err)
+	}
+
+	_, err = ColumnIndex(columnNames, "x")
+	if err == nil || err.Error() != "x is not found" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestColumnIndexes_duplicate(t *testing.T) {
+
+	columnNames := []string{"id", "name", "id", "id"}
+
+	tests := []struct {
+		selectors []string
+		expect    []int
+	}{
+		{[]string{"id"}, []int{0, 2, 3}}, // 同じ名前のカラム全て
+		{[]string{"id#2"}, []int{2}},
+		{[]string{"id#3", "id#1"}, []int{3, 0}},
+		{[]string{"!id#2"}, []int{0, 1, 3}},
+	}
+
+	for _, test := range tests {
+		result, err := ColumnIndexes(columnNames, test.selectors)
+		if err != nil {
+			t.Fatal("failed test\n", test.selectors, err)
+		}
+		if !reflect.DeepEqual(result, test.expect) {
+			t.Fatal("failed test\n", test.selectors, result)
+		}
+	}
+
+	_, err := ColumnIndexes(columnNames, []string{"id#4"})
+	if _, ok := err.(*ColumnNotFoundError); !ok {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestColumnIndex_duplicate(t *testing.T) {
+
+	columnNames := []string{"id", "name", "id"}
+
+	index, err := ColumnIndex(columnNames, "id#2")
+	if err != nil || index != 2 {
+		t.Fatal("failed test\n", index, err)
+	}
+
+	_, err = ColumnIndex(columnNames, "id")
+	if err == nil || err.Error() != "id is duplicated in the header (specify like id#2, or use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestDedupeColumnNames(t *testing.T) {
+
+	tests := []struct {
+		columnNames []string
+		expect      []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]string{"a", "a", "a"}, []string{"a", "a_2", "a_3"}},
+		{[]string{"a", "a_2", "a"}, []string{"a", "a_2", "a_3"}}, // 既存のカラム名とは衝突させない
+		{[]string{"", ""}, []string{"", "_2"}},
+	}
+
+	for _, test := range tests {
+		result := DedupeColumnNames(test.columnNames)
+		if !reflect.DeepEqual(result, test.expect) {
+			t.Fatal("failed test\n", test.columnNames, result)
+		}
+	}
+}
+
+func TestCheckDuplicateColumnNames(t *testing.T) {
+
+	err := CheckDuplicateColumnNames([]string{"a", "b"})
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	err = CheckDuplicateColumnNames([]string{"a", "b", "a", "b", "a"})
+	if err == nil || err.Error() != "duplicate column names in the header: a, b (use --dedupe-headers)" {
+		t.Fatal("failed test\n", err)
+	}
+}
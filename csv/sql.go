@@ -0,0 +1,357 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	SqlDialectPostgresql = "postgresql"
+	SqlDialectMysql      = "mysql"
+	SqlDialectSqlite     = "sqlite"
+)
+
+const (
+	SqlModeInsert = "insert"
+	SqlModeCopy   = "copy"
+)
+
+type SqlOptions struct {
+	Dialect       string
+	Mode          string
+	Table         string
+	BatchSize     int
+	NoCreateTable bool
+	// カラムの型 (指定が無いカラムは文字列として扱う)
+	Schema *Schema
+}
+
+// 時刻を含むレイアウトの日付の型 (typesのキー)
+const sqlDatetime = "datetime"
+
+// 時刻を含む日付の値の形式 (タイムゾーンを含む場合はUTCに変換)
+const sqlDatetimeLayout = "2006-01-02 15:04:05.999999999"
+
+// 方言ごとの違い
+type sqlDialect struct {
+	identifierQuote string
+	types           map[string]string
+	trueValue       string
+	falseValue      string
+	escapeString    func(value string) string
+}
+
+var sqlDialects = map[string]*sqlDialect{
+	SqlDialectPostgresql: {
+		identifierQuote: `"`,
+		types: map[string]string{
+			TypeInteger: "BIGINT",
+			TypeDecimal: "NUMERIC",
+			TypeBoolean: "BOOLEAN",
+			TypeDate:    "DATE",
+			sqlDatetime: "TIMESTAMP",
+			TypeString:  "TEXT",
+		},
+		trueValue:    "TRUE",
+		falseValue:   "FALSE",
+		escapeString: func(value string) string { return strings.ReplaceAll(value, "'", "''") },
+	},
+	SqlDialectMysql: {
+		identifierQuote: "`",
+		types: map[string]string{
+			TypeInteger: "BIGINT",
+			TypeDecimal: "DOUBLE",
+			TypeBoolean: "BOOLEAN",
+			TypeDate:    "DATE",
+			sqlDatetime: "DATETIME",
+			TypeString:  "TEXT",
+		},
+		trueValue:  "TRUE",
+		falseValue: "FALSE",
+		// MySQLはバックスラッシュもエスケープ文字として扱われる
+		escapeString: func(value string) string {
+			return strings.NewReplacer(`\`, `\\`, "'", "''").Replace(value)
+		},
+	},
+	SqlDialectSqlite: {
+		identifierQuote: `"`,
+		types: map[string]string{
+			TypeInteger: "INTEGER",
+			TypeDecimal: "REAL",
+			TypeBoolean: "INTEGER",
+			TypeDate:    "TEXT",
+			sqlDatetime: "TEXT",
+			TypeString:  "TEXT",
+		},
+		trueValue:    "1",
+		falseValue:   "0",
+		escapeString: func(value string) string { return strings.ReplaceAll(value, "'", "''") },
+	},
+}
+
+// CREATE TABLEとINSERT(もしくはCOPY)のSQLとして書き込むWriter
+// 1レコード目はヘッダとしてカラム名に使う
+type SqlWriter struct {
+	w           *bufio.Writer
+	options     SqlOptions
+	dialect     *sqlDialect
+	columnNames []string
+	columns     []*ColumnSchema
+	batch       []string
+	copyStarted bool
+}
+
+func NewSqlWriter(w io.Writer, options SqlOptions) (*SqlWriter, error) {
+
+	dialect, ok := sqlDialects[options.Dialect]
+	if !ok {
+		return nil, fmt.Errorf("dialect %s is not supported", options.Dialect)
+	}
+
+	switch options.Mode {
+	case SqlModeInsert:
+	case SqlModeCopy:
+		if options.Dialect != SqlDialectPostgresql {
+			return nil, fmt.Errorf("copy mode is only available for postgresql")
+		}
+	default:
+		return nil, fmt.Errorf("mode %s is not supported", options.Mode)
+	}
+
+	if options.BatchSize < 1 {
+		options.BatchSize = 1
+	}
+
+	return &SqlWriter{
+		w:       bufio.NewWriter(w),
+		options: options,
+		dialect: dialect,
+	}, nil
+}
+
+func (w *SqlWriter) Write(record []string) error {
+
+	if w.columnNames == nil {
+		// 同じ名前のカラムは作れないので一意に
+		w.columnNames = DedupeColumnNames(record)
+		w.columns = w.resolveColumns()
+
+		if w.options.NoCreateTable {
+			return nil
+		}
+		return w.writeCreateTable()
+	}
+
+	if w.options.Mode == SqlModeCopy {
+		return w.writeCopyRow(record)
+	}
+
+	values := []string{}
+	for i, column := range w.columns {
+		value, err := w.literal(column, w.columnNames[i], cellAt(record, i))
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+	w.batch = append(w.batch, "("+strings.Join(values, ", ")+")")
+
+	if len(w.batch) >= w.options.BatchSize {
+		return w.writeInsert()
+	}
+	return nil
+}
+
+func (w *SqlWriter) Flush() error {
+
+	if w.options.Mode == SqlModeCopy {
+		if w.copyStarted {
+			if _, err := w.w.WriteString("\\.\n"); err != nil {
+				return err
+			}
+		}
+	} else if len(w.batch) > 0 {
+		if err := w.writeInsert(); err != nil {
+			return err
+		}
+	}
+
+	return w.w.Flush()
+}
+
+func (w *SqlWriter) resolveColumns() []*ColumnSchema {
+
+	columns := []*ColumnSchema{}
+	for _, name := range w.columnNames {
+		column := &ColumnSchema{Name: name, Type: TypeString}
+		if w.options.Schema != nil {
+			for _, c := range w.options.Schema.Columns {
+				if c.Name == name {
+					column = c
+					break
+				}
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func (w *SqlWriter) writeCreateTable() error {
+
+	definitions := []string{}
+	for i, column := range w.columns {
+		definition := "  " + w.identifier(w.columnNames[i]) + " " + w.columnType(column)
+		if column.NotNull {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+
+	_, err := w.w.WriteString(
+		"CREATE TABLE " + w.tableName() + " (\n" + strings.Join(definitions, ",\n") + "\n);\n")
+	return err
+}
+
+func (w *SqlWriter) writeInsert() error {
+
+	_, err := w.w.WriteString(
+		"INSERT INTO " + w.tableName() + " (" + w.columnList() + ") VALUES\n" + strings.Join(w.batch, ",\n") + ";\n")
+	w.batch = nil
+	return err
+}
+
+// PostgreSQLのCOPYのテキスト形式 (タブ区切りで、NULLは\N)
+func (w *SqlWriter) writeCopyRow(record []string) error {
+
+	if !w.copyStarted {
+		w.copyStarted = true
+		if _, err := w.w.WriteString("COPY " + w.tableName() + " (" + w.columnList() + ") FROM stdin;\n"); err != nil {
+			return err
+		}
+	}
+
+	values := []string{}
+	for i, column := range w.columns {
+		value, err := w.normalize(column, w.columnNames[i], cellAt(record, i))
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			values = append(values, `\N`)
+			continue
+		}
+		values = append(values, strings.NewReplacer(
+			`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(value))
+	}
+
+	_, err := w.w.WriteString(strings.Join(values, "\t") + "\n")
+	return err
+}
+
+// 値をSQLのリテラルに (空の値はNULL)
+func (w *SqlWriter) literal(column *ColumnSchema, name string, value string) (string, error) {
+
+	value, err := w.normalize(column, name, value)
+	if err != nil {
+		return "", err
+	}
+
+	if value == "" {
+		return "NULL", nil
+	}
+
+	switch column.Type {
+	case TypeInteger, TypeDecimal, TypeBoolean:
+		return value, nil
+	}
+
+	return "'" + w.dialect.escapeString(value) + "'", nil
+}
+
+// カラムの型に合わせて値を変換 (真偽値はtrue/falseの表記、日付はYYYY-MM-DD(時刻を含む場合はYYYY-MM-DD hh:mm:ss)に揃える)
+func (w *SqlWriter) normalize(column *ColumnSchema, name string, value string) (string, error) {
+
+	if value == "" {
+		return "", nil
+	}
+
+	switch column.Type {
+	case TypeInteger:
+		if integerPattern.MatchString(value) {
+			return value, nil
+		}
+	case TypeDecimal:
+		if decimalPattern.MatchString(value) {
+			return value, nil
+		}
+	case TypeBoolean:
+		if isBoolean(value) {
+			if strings.ToLower(value) == "true" {
+				if w.options.Mode == SqlModeCopy {
+					return "t", nil
+				}
+				return w.dialect.trueValue, nil
+			}
+			if w.options.Mode == SqlModeCopy {
+				return "f", nil
+			}
+			return w.dialect.falseValue, nil
+		}
+	case TypeDate:
+		layout := column.Layout
+		if layout == "" {
+			layout = DefaultDateLayout
+		}
+		if t, err := time.Parse(layout, value); err == nil {
+			if layoutHasTime(layout) {
+				return t.UTC().Format(sqlDatetimeLayout), nil
+			}
+			return t.Format(DefaultDateLayout), nil
+		}
+	default:
+		return value, nil
+	}
+
+	return "", fmt.Errorf("value %s of the column %s cannot be written as %s", value, name, column.Type)
+}
+
+func (w *SqlWriter) columnType(column *ColumnSchema) string {
+
+	if column.Type == TypeDate && layoutHasTime(column.Layout) {
+		return w.dialect.types[sqlDatetime]
+	}
+	if t, ok := w.dialect.types[column.Type]; ok {
+		return t
+	}
+	// enumやregexは文字列として
+	return w.dialect.types[TypeString]
+}
+
+func (w *SqlWriter) columnList() string {
+
+	names := []string{}
+	for _, name := range w.columnNames {
+		names = append(names, w.identifier(name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// スキーマ名を含む場合(schema.table)は、それぞれを識別子として扱う
+func (w *SqlWriter) tableName() string {
+
+	names := []string{}
+	for _, name := range strings.Split(w.options.Table, ".") {
+		names = append(names, w.identifier(name))
+	}
+	return strings.Join(names, ".")
+}
+
+func (w *SqlWriter) identifier(name string) string {
+
+	quote := w.dialect.identifierQuote
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
@@ -0,0 +1,252 @@
+package csv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func writeSql(t *testing.T, options SqlOptions, records [][]string) string {
+
+	b := &bytes.Buffer{}
+	w, err := NewSqlWriter(b, options)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	return b.String()
+}
+
+var sqlTestSchema = &Schema{
+	Columns: []*ColumnSchema{
+		{Name: "ID", Type: TypeInteger, NotNull: true},
+		{Name: "Name", Type: TypeString},
+		{Name: "Price", Type: TypeDecimal},
+		{Name: "Active", Type: TypeBoolean},
+		{Name: "Date", Type: TypeDate, Layout: "2006/01/02"},
+	},
+}
+
+var sqlTestRecords = [][]string{
+	{"ID", "Name", "Price", "Active", "Date"},
+	{"1", "O'Brien", "1.5", "true", "2022/01/02"},
+	{"2", `a\b`, "", "FALSE", ""},
+	{"3", "x\ty", "3", "true", "2022/12/31"},
+}
+
+func TestSqlWriter_postgresql(t *testing.T) {
+
+	result := writeSql(t, SqlOptions{
+		Dialect:   SqlDialectPostgresql,
+		Mode:      SqlModeInsert,
+		Table:     "public.users",
+		BatchSize: 2,
+		Schema:    sqlTestSchema,
+	}, sqlTestRecords)
+
+	expect := `CREATE TABLE "public"."users" (
+  "ID" BIGINT NOT NULL,
+  "Name" TEXT,
+  "Price" NUMERIC,
+  "Active" BOOLEAN,
+  "Date" DATE
+);
+INSERT INTO "public"."users" ("ID", "Name", "Price", "Active", "Date") VALUES
+(1, 'O''Brien', 1.5, TRUE, '2022-01-02'),
+(2, 'a\b', NULL, FALSE, NULL);
+INSERT INTO "public"."users" ("ID", "Name", "Price", "Active", "Date") VALUES
+(3, 'x` + "\t" + `y', 3, TRUE, '2022-12-31');
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_mysql(t *testing.T) {
+
+	result := writeSql(t, SqlOptions{
+		Dialect:   SqlDialectMysql,
+		Mode:      SqlModeInsert,
+		Table:     "users",
+		BatchSize: 100,
+		Schema:    sqlTestSchema,
+	}, sqlTestRecords[:3])
+
+	expect := "CREATE TABLE `users` (\n" +
+		"  `ID` BIGINT NOT NULL,\n" +
+		"  `Name` TEXT,\n" +
+		"  `Price` DOUBLE,\n" +
+		"  `Active` BOOLEAN,\n" +
+		"  `Date` DATE\n" +
+		");\n" +
+		"INSERT INTO `users` (`ID`, `Name`, `Price`, `Active`, `Date`) VALUES\n" +
+		"(1, 'O''Brien', 1.5, TRUE, '2022-01-02'),\n" +
+		"(2, 'a\\\\b', NULL, FALSE, NULL);\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_sqlite(t *testing.T) {
+
+	result := writeSql(t, SqlOptions{
+		Dialect:       SqlDialectSqlite,
+		Mode:          SqlModeInsert,
+		Table:         `my"table`,
+		BatchSize:     1,
+		NoCreateTable: true,
+		Schema:        sqlTestSchema,
+	}, sqlTestRecords[:3])
+
+	expect := `INSERT INTO "my""table" ("ID", "Name", "Price", "Active", "Date") VALUES
+(1, 'O''Brien', 1.5, 1, '2022-01-02');
+INSERT INTO "my""table" ("ID", "Name", "Price", "Active", "Date") VALUES
+(2, 'a\b', NULL, 0, NULL);
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_copy(t *testing.T) {
+
+	result := writeSql(t, SqlOptions{
+		Dialect:       SqlDialectPostgresql,
+		Mode:          SqlModeCopy,
+		Table:         "users",
+		NoCreateTable: true,
+		Schema:        sqlTestSchema,
+	}, append(sqlTestRecords, []string{"4", "a\r\nb", "", "", ""}))
+
+	expect := "COPY \"users\" (\"ID\", \"Name\", \"Price\", \"Active\", \"Date\") FROM stdin;\n" +
+		"1\tO'Brien\t1.5\tt\t2022-01-02\n" +
+		"2\ta\\\\b\t\\N\tf\t\\N\n" +
+		"3\tx\\ty\t3\tt\t2022-12-31\n" +
+		"4\ta\\r\\nb\t\\N\t\\N\t\\N\n" +
+		"\\.\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_datetime(t *testing.T) {
+
+	schema := &Schema{
+		Columns: []*ColumnSchema{
+			{Name: "Local", Type: TypeDate, Layout: "2006-01-02 15:04:05"},
+			{Name: "Zoned", Type: TypeDate, Layout: time.RFC3339},
+		},
+	}
+	records := [][]string{
+		{"Local", "Zoned"},
+		{"2024-01-02 10:30:00", "2024-01-02T10:30:00.5+09:00"},
+	}
+
+	result := writeSql(t, SqlOptions{
+		Dialect: SqlDialectPostgresql,
+		Mode:    SqlModeInsert,
+		Table:   "logs",
+		Schema:  schema,
+	}, records)
+
+	// 時刻は落とさない (タイムゾーンを含む場合はUTCに)
+	expect := `CREATE TABLE "logs" (
+  "Local" TIMESTAMP,
+  "Zoned" TIMESTAMP
+);
+INSERT INTO "logs" ("Local", "Zoned") VALUES
+('2024-01-02 10:30:00', '2024-01-02 01:30:00.5');
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+
+	result = writeSql(t, SqlOptions{
+		Dialect: SqlDialectMysql,
+		Mode:    SqlModeInsert,
+		Table:   "logs",
+		Schema:  schema,
+	}, records[:1])
+
+	expect = "CREATE TABLE `logs` (\n" +
+		"  `Local` DATETIME,\n" +
+		"  `Zoned` DATETIME\n" +
+		");\n"
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_noSchema(t *testing.T) {
+
+	result := writeSql(t, SqlOptions{
+		Dialect:   SqlDialectPostgresql,
+		Mode:      SqlModeInsert,
+		Table:     "users",
+		BatchSize: 100,
+	}, [][]string{
+		{"ID", "ID"},
+		{"1", ""},
+	})
+
+	expect := `CREATE TABLE "users" (
+  "ID" TEXT,
+  "ID_2" TEXT
+);
+INSERT INTO "users" ("ID", "ID_2") VALUES
+('1', NULL);
+`
+	if result != expect {
+		t.Fatal("failed test\n", result)
+	}
+}
+
+func TestSqlWriter_invalidValue(t *testing.T) {
+
+	w, err := NewSqlWriter(&bytes.Buffer{}, SqlOptions{
+		Dialect:   SqlDialectPostgresql,
+		Mode:      SqlModeInsert,
+		Table:     "users",
+		BatchSize: 100,
+		Schema:    sqlTestSchema,
+	})
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if err := w.Write(sqlTestRecords[0]); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	err = w.Write([]string{"a", "", "", "", ""})
+	if err == nil || err.Error() != "value a of the column ID cannot be written as integer" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestNewSqlWriter_invalidOptions(t *testing.T) {
+
+	tests := []struct {
+		options SqlOptions
+		expect  string
+	}{
+		{SqlOptions{Dialect: "oracle", Mode: SqlModeInsert}, "dialect oracle is not supported"},
+		{SqlOptions{Dialect: SqlDialectMysql, Mode: SqlModeCopy}, "copy mode is only available for postgresql"},
+		{SqlOptions{Dialect: SqlDialectSqlite, Mode: "upsert"}, "mode upsert is not supported"},
+	}
+
+	for _, test := range tests {
+		_, err := NewSqlWriter(&bytes.Buffer{}, test.options)
+		if err == nil || err.Error() != test.expect {
+			t.Fatal("failed test\n", err)
+		}
+	}
+}
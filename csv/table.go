@@ -7,7 +7,6 @@ import (
 	"os"
 
 	"github.com/boltdb/bolt"
-	"golang.org/x/exp/slices"
 )
 
 type CsvTable interface {
@@ -62,10 +61,17 @@ func LoadCsvMemoryTable(reader CsvReader, keyColumnName string) (CsvTable, error
 		return nil, err
 	}
 
-	primaryColumnIndex := slices.Index(headers, keyColumnName)
-	if primaryColumnIndex == -1 {
-		return nil, fmt.Errorf("%s is not found", keyColumnName)
+	// カラム名をキーとしたmapで値を返すので、重複したカラム名は扱えない
+	if err := CheckDuplicateColumnNames(headers); err != nil {
+		return nil, err
+	}
+
+	primaryColumnIndex, err := ColumnIndex(headers, keyColumnName)
+	if err != nil {
+		return nil, err
 	}
+	// 番号などで指定された場合も、実際のカラム名で扱う
+	keyColumnName = headers[primaryColumnIndex]
 
 	rows := make(map[string][]string)
 	for {
@@ -99,6 +105,7 @@ type fileTable struct {
 	columnNames   []string
 	dbPath        string
 	db            *bolt.DB
+	codec         *tempValueCodec
 }
 
 func (t *fileTable) Find(key string) (map[string]string, error) {
@@ -120,7 +127,11 @@ func (t *fileTable) Find(key string) (map[string]string, error) {
 
 		v := b.Get([]byte(key))
 		if v != nil {
-			json.Unmarshal(v, &row)
+			rowJson, err := t.codec.decode(v)
+			if err != nil {
+				return err
+			}
+			json.Unmarshal(rowJson, &row)
 		}
 
 		return nil
@@ -154,6 +165,8 @@ func (t *fileTable) ColumnNames() []string {
 
 func (t *fileTable) Close() error {
 
+	t.codec.Close()
+
 	if t.db != nil {
 		err := t.db.Close()
 		if err != nil {
@@ -164,16 +177,29 @@ func (t *fileTable) Close() error {
 	return os.Remove(t.dbPath)
 }
 
-func LoadCsvFileTable(reader CsvReader, keyColumnName string) (CsvTable, error) {
+// compressにtrueを指定した場合、一時ファイルに格納する行を圧縮する
+func LoadCsvFileTable(reader CsvReader, keyColumnName string, compress bool) (CsvTable, error) {
 
 	headers, err := reader.Read()
 	if err != nil {
 		return nil, err
 	}
 
-	primaryColumnIndex := slices.Index(headers, keyColumnName)
-	if primaryColumnIndex == -1 {
-		return nil, fmt.Errorf("%s is not found", keyColumnName)
+	// カラム名をキーとしたmapで値を返すので、重複したカラム名は扱えない
+	if err := CheckDuplicateColumnNames(headers); err != nil {
+		return nil, err
+	}
+
+	primaryColumnIndex, err := ColumnIndex(headers, keyColumnName)
+	if err != nil {
+		return nil, err
+	}
+	// 番号などで指定された場合も、実際のカラム名で扱う
+	keyColumnName = headers[primaryColumnIndex]
+
+	codec, err := newTempValueCodec(compress)
+	if err != nil {
+		return nil, err
 	}
 
 	dbFile, err := os.CreateTemp("", "csvdb")
@@ -224,7 +250,7 @@ func LoadCsvFileTable(reader CsvReader, keyColumnName string) (CsvTable, error)
 					return err
 				}
 
-				err = b.Put([]byte(key), []byte(rowJson))
+				err = b.Put([]byte(key), codec.encode(rowJson))
 				if err != nil {
 					return err
 				}
@@ -242,5 +268,6 @@ func LoadCsvFileTable(reader CsvReader, keyColumnName string) (CsvTable, error)
 		keyColumnName: keyColumnName,
 		columnNames:   headers,
 		dbPath:        dbFile.Name(),
+		codec:         codec,
 	}, nil
 }
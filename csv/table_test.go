@@ -167,7 +167,7 @@ func TestLoadCsvFileTable(t *testing.T) {
 `
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	table, err := LoadCsvFileTable(r, "ID")
+	table, err := LoadCsvFileTable(r, "ID", false)
 	if err != nil {
 		t.Fatal("failed test\n", err)
 	}
@@ -207,6 +207,39 @@ func TestLoadCsvFileTable(t *testing.T) {
 	}
 }
 
+func TestLoadCsvFileTable_compress(t *testing.T) {
+
+	s := `ID,Name
+1,Yamada
+2,"Hanako, Sato"
+`
+	r := NewCsvReader(strings.NewReader(s), Format{})
+
+	table, err := LoadCsvFileTable(r, "ID", true)
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	defer table.Close()
+
+	result, err := table.Find("2")
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if !reflect.DeepEqual(result, map[string]string{"ID": "2", "Name": "Hanako, Sato"}) {
+		t.Fatal("failed test\n", result)
+	}
+
+	result, err = table.Find("3")
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	if result != nil {
+		t.Fatal("failed test\n", result)
+	}
+}
+
 func TestLoadCsvFileTable_duplicateKey(t *testing.T) {
 
 	s := `ID,Name,Height,Weight
@@ -216,7 +249,7 @@ func TestLoadCsvFileTable_duplicateKey(t *testing.T) {
 `
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileTable(r, "ID")
+	_, err := LoadCsvFileTable(r, "ID", false)
 	if err == nil || err.Error() != "ID:1 is duplicated" {
 		t.Fatal("failed test\n", err)
 	}
@@ -230,7 +263,7 @@ func TestLoadCsvFileTable_keyColumnNotFound(t *testing.T) {
 `
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileTable(r, "id")
+	_, err := LoadCsvFileTable(r, "id", false)
 	if err == nil || err.Error() != "id is not found" {
 		t.Fatal("failed test\n", err)
 	}
@@ -241,7 +274,7 @@ func TestLoadCsvFileTable_empty(t *testing.T) {
 	s := ""
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileTable(r, "ID")
+	_, err := LoadCsvFileTable(r, "ID", false)
 	if err != io.EOF {
 		t.Fatal("failed test\n", err)
 	}
@@ -252,7 +285,7 @@ func TestLoadCsvFileTable_changeLineOnly(t *testing.T) {
 	s := "\n"
 	r := NewCsvReader(strings.NewReader(s), Format{})
 
-	_, err := LoadCsvFileTable(r, "ID")
+	_, err := LoadCsvFileTable(r, "ID", false)
 	if err == nil || err.Error() != "ID is not found" {
 		t.Fatal("failed test\n", err)
 	}
@@ -270,7 +303,7 @@ func TestLoadCsvFileTable_big(t *testing.T) {
 
 	r := NewCsvReader(strings.NewReader(strings.Join(s[:], "\n")), Format{})
 
-	table, err := LoadCsvFileTable(r, "ID")
+	table, err := LoadCsvFileTable(r, "ID", false)
 	if err != nil {
 		t.Fatal("failed test\n", err)
 	}
@@ -0,0 +1,335 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	InputFormatXlsx  = "xlsx"
+	OutputFormatXlsx = "xlsx"
+)
+
+// シートの内容をレコードとして返すReader
+type xlsxReader struct {
+	r      io.Reader
+	sheet  string
+	file   *excelize.File
+	rows   *excelize.Rows
+	width  int
+	header bool
+}
+
+func newXlsxReader(r io.Reader, f Format) *xlsxReader {
+
+	return &xlsxReader{
+		r:     r,
+		sheet: f.Sheet,
+	}
+}
+
+func (r *xlsxReader) Read() ([]string, error) {
+
+	if r.rows == nil {
+		if err := r.open(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		r.close()
+		return nil, io.EOF
+	}
+
+	record, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.header {
+		r.header = true
+		r.width = len(record)
+		return record, nil
+	}
+
+	// 末尾の空セルは省略されるので、ヘッダの項目数に合わせる
+	for len(record) < r.width {
+		record = append(record, "")
+	}
+
+	return record, nil
+}
+
+func (r *xlsxReader) open() error {
+
+	file, err := excelize.OpenReader(r.r)
+	if err != nil {
+		return errors.Wrap(err, "invalid xlsx file")
+	}
+
+	sheet, err := findSheet(file.GetSheetList(), r.sheet)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.rows = rows
+	return nil
+}
+
+func (r *xlsxReader) close() {
+
+	r.rows.Close()
+	r.file.Close()
+}
+
+// シート名もしくはシート番号(1始まり)でシートを特定 (指定が無い場合は最初のシート)
+func findSheet(sheets []string, sheet string) (string, error) {
+
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("no sheet in the xlsx file")
+	}
+
+	if sheet == "" {
+		return sheets[0], nil
+	}
+
+	if slices.Contains(sheets, sheet) {
+		return sheet, nil
+	}
+
+	if number, err := strconv.Atoi(sheet); err == nil && number >= 1 && number <= len(sheets) {
+		return sheets[number-1], nil
+	}
+
+	return "", fmt.Errorf("sheet %s is not found", sheet)
+}
+
+// 複数のシートに書き込むためのブック
+type XlsxWorkbook struct {
+	w          io.Writer
+	file       *excelize.File
+	inferTypes bool
+	sheets     []*xlsxSheetWriter
+	flushed    bool
+}
+
+func NewXlsxWorkbook(w io.Writer, f Format) *XlsxWorkbook {
+
+	return &XlsxWorkbook{
+		w:          w,
+		file:       excelize.NewFile(),
+		inferTypes: f.InferTypes,
+	}
+}
+
+// シートを追加して、そのシートに書き込むWriterを返す
+// シート名として使えない文字は置き換え、既存のシート名と重複する場合は番号を付与する
+func (b *XlsxWorkbook) Sheet(name string) (CsvWriter, error) {
+
+	name = b.uniqueSheetName(sanitizeSheetName(name))
+
+	if len(b.sheets) == 0 {
+		// 新規のブックに最初からあるシートを使う
+		if err := b.file.SetSheetName(b.file.GetSheetList()[0], name); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := b.file.NewSheet(name); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := b.file.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &xlsxSheetWriter{
+		name:       name,
+		stream:     stream,
+		inferTypes: b.inferTypes,
+	}
+	b.sheets = append(b.sheets, sheet)
+
+	return sheet, nil
+}
+
+// 全シートの内容をブックとして書き出す
+func (b *XlsxWorkbook) Flush() error {
+
+	if b.flushed {
+		return nil
+	}
+	b.flushed = true
+
+	if len(b.sheets) == 0 {
+		// 空のブックにならないように
+		if _, err := b.Sheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+
+	for _, sheet := range b.sheets {
+		if err := sheet.stream.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := b.file.Write(b.w); err != nil {
+		return err
+	}
+
+	return b.file.Close()
+}
+
+func (b *XlsxWorkbook) uniqueSheetName(name string) string {
+
+	exists := func(name string) bool {
+		for _, sheet := range b.sheets {
+			// シート名は大文字小文字を区別しない
+			if strings.EqualFold(sheet.name, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !exists(name) {
+		return name
+	}
+
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate := truncateRunes(name, xlsxMaxSheetNameLength-utf8.RuneCountInString(suffix)) + suffix
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+const xlsxMaxSheetNameLength = 31
+
+func sanitizeSheetName(name string) string {
+
+	name = strings.NewReplacer(
+		":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "_", "]", "_").Replace(name)
+
+	// 先頭と末尾のアポストロフィは使えない
+	name = strings.Trim(name, "'")
+
+	if name == "" {
+		return "(empty)"
+	}
+
+	return truncateRunes(name, xlsxMaxSheetNameLength)
+}
+
+func truncateRunes(s string, max int) string {
+
+	runes := []rune(s)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
+
+type xlsxSheetWriter struct {
+	name       string
+	stream     *excelize.StreamWriter
+	inferTypes bool
+	row        int
+}
+
+func (w *xlsxSheetWriter) Write(record []string) error {
+
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(record))
+	for i, value := range record {
+		values[i] = w.cellValue(value)
+	}
+
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxSheetWriter) Flush() error {
+
+	// ブック単位で書き出すので、シート単位では何もしない
+	return nil
+}
+
+func (w *xlsxSheetWriter) cellValue(value string) interface{} {
+
+	if !w.inferTypes {
+		return value
+	}
+
+	switch {
+	case value == "":
+		return nil
+	case value == "true" || value == "false":
+		return value == "true"
+	case jsonNumberPattern.MatchString(value):
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	}
+
+	return value
+}
+
+// 1シートのみのブックとして書き込むWriter
+type xlsxWriter struct {
+	book  *XlsxWorkbook
+	sheet CsvWriter
+}
+
+func newXlsxWriter(w io.Writer, f Format) *xlsxWriter {
+
+	return &xlsxWriter{
+		book: NewXlsxWorkbook(w, f),
+	}
+}
+
+func (w *xlsxWriter) Write(record []string) error {
+
+	if w.sheet == nil {
+		sheet, err := w.book.Sheet("Sheet1")
+		if err != nil {
+			return err
+		}
+		w.sheet = sheet
+	}
+
+	return w.sheet.Write(record)
+}
+
+func (w *xlsxWriter) Flush() error {
+
+	return w.book.Flush()
+}
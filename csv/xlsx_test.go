@@ -0,0 +1,158 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXlsxWriterReader(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatXlsx, InferTypes: true})
+
+	records := [][]string{
+		{"ID", "Name", "Price", "Active"},
+		{"1", "Yamada", "10.5", "true"},
+		{"007", "", "", "false"},
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	// 型を推定したセルになっていること
+	file, err := excelize.OpenReader(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+	// 型の指定が無いセルは数値
+	cellType, _ := file.GetCellType("Sheet1", "A2")
+	if cellType != excelize.CellTypeUnset && cellType != excelize.CellTypeNumber {
+		t.Fatal("failed test\n", cellType)
+	}
+	cellType, _ = file.GetCellType("Sheet1", "A3")
+	if cellType == excelize.CellTypeUnset || cellType == excelize.CellTypeNumber {
+		t.Fatal("failed test\n", cellType)
+	}
+
+	r := NewCsvReader(bytes.NewReader(b.Bytes()), Format{InputFormat: InputFormatXlsx})
+
+	expects := [][]string{
+		{"ID", "Name", "Price", "Active"},
+		{"1", "Yamada", "10.5", "TRUE"},
+		{"007", "", "", "FALSE"},
+	}
+
+	for _, expect := range expects {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if !reflect.DeepEqual(record, expect) {
+			t.Fatal("failed test\n", record)
+		}
+	}
+
+	_, err = r.Read()
+	if err != io.EOF {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestXlsxReader_sheet(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	book := NewXlsxWorkbook(b, Format{})
+
+	sheet1, _ := book.Sheet("A")
+	sheet1.Write([]string{"ID"})
+	sheet1.Write([]string{"1"})
+	sheet2, _ := book.Sheet("B")
+	sheet2.Write([]string{"ID", "Name"})
+	sheet2.Write([]string{"2"})
+	book.Flush()
+
+	tests := []struct {
+		sheet   string
+		expects [][]string
+	}{
+		{"", [][]string{{"ID"}, {"1"}}},
+		{"B", [][]string{{"ID", "Name"}, {"2", ""}}}, // ヘッダの項目数に合わせる
+		{"2", [][]string{{"ID", "Name"}, {"2", ""}}},
+	}
+
+	for _, test := range tests {
+		r := NewCsvReader(bytes.NewReader(b.Bytes()), Format{InputFormat: InputFormatXlsx, Sheet: test.sheet})
+
+		for _, expect := range test.expects {
+			record, err := r.Read()
+			if err != nil {
+				t.Fatal("failed test\n", test.sheet, err)
+			}
+			if !reflect.DeepEqual(record, expect) {
+				t.Fatal("failed test\n", test.sheet, record)
+			}
+		}
+
+		_, err := r.Read()
+		if err != io.EOF {
+			t.Fatal("failed test\n", test.sheet, err)
+		}
+	}
+}
+
+func TestXlsxReader_sheetNotFound(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	w := NewCsvWriter(b, Format{OutputFormat: OutputFormatXlsx})
+	w.Write([]string{"ID"})
+	w.Flush()
+
+	r := NewCsvReader(bytes.NewReader(b.Bytes()), Format{InputFormat: InputFormatXlsx, Sheet: "3"})
+
+	_, err := r.Read()
+	if err == nil || err.Error() != "sheet 3 is not found" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestXlsxReader_invalid(t *testing.T) {
+
+	r := NewCsvReader(bytes.NewReader([]byte("ID,Name\n")), Format{InputFormat: InputFormatXlsx})
+
+	_, err := r.Read()
+	if err == nil || err.Error() != "invalid xlsx file: zip: not a valid zip file" {
+		t.Fatal("failed test\n", err)
+	}
+}
+
+func TestXlsxWorkbook_sheetName(t *testing.T) {
+
+	b := &bytes.Buffer{}
+	book := NewXlsxWorkbook(b, Format{})
+
+	for _, name := range []string{"a/b", "A_B", "", "0123456789012345678901234567890123"} {
+		if _, err := book.Sheet(name); err != nil {
+			t.Fatal("failed test\n", err)
+		}
+	}
+	book.Flush()
+
+	file, err := excelize.OpenReader(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal("failed test\n", err)
+	}
+
+	expect := []string{"a_b", "A_B (2)", "(empty)", "0123456789012345678901234567890"}
+	if !reflect.DeepEqual(file.GetSheetList(), expect) {
+		t.Fatal("failed test\n", file.GetSheetList())
+	}
+}
@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+type Counter interface {
+	Add(value string)
+	Distinct() int
+	Top(n int) []ItemCount
+}
+
+type QuantileEstimator interface {
+	Add(value float64)
+	Quantile(q float64) float64
+}
+
+// 全ての値を保持して正確に集計
+type exactCounter struct {
+	counts map[string]int
+}
+
+func NewExactCounter() Counter {
+	return &exactCounter{
+		counts: map[string]int{},
+	}
+}
+
+func (c *exactCounter) Add(value string) {
+	c.counts[value]++
+}
+
+func (c *exactCounter) Distinct() int {
+	return len(c.counts)
+}
+
+func (c *exactCounter) Top(n int) []ItemCount {
+
+	items := []ItemCount{}
+	for value, count := range c.counts {
+		items = append(items, ItemCount{Value: value, Count: count})
+	}
+
+	SortItemCounts(items)
+
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// 値を保持せずに推定で集計
+type approxCounter struct {
+	hll  *HyperLogLog
+	topK *TopK
+}
+
+func NewApproxCounter() Counter {
+	return &approxCounter{
+		hll:  NewHyperLogLog(14),
+		topK: NewTopK(1000),
+	}
+}
+
+func (c *approxCounter) Add(value string) {
+	c.hll.Add(value)
+	c.topK.Add(value)
+}
+
+func (c *approxCounter) Distinct() int {
+	return c.hll.Count()
+}
+
+func (c *approxCounter) Top(n int) []ItemCount {
+	return c.topK.Top(n)
+}
+
+// 全ての値を保持して正確に分位数を算出
+type exactQuantiles struct {
+	values []float64
+	sorted bool
+}
+
+func NewExactQuantiles() QuantileEstimator {
+	return &exactQuantiles{}
+}
+
+func (e *exactQuantiles) Add(value float64) {
+	e.values = append(e.values, value)
+	e.sorted = false
+}
+
+func (e *exactQuantiles) Quantile(q float64) float64 {
+
+	if len(e.values) == 0 {
+		return math.NaN()
+	}
+
+	if !e.sorted {
+		sort.Float64s(e.values)
+		e.sorted = true
+	}
+
+	// 前後の値から線形補間
+	position := q * float64(len(e.values)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	return e.values[lower] + (e.values[upper]-e.values[lower])*(position-float64(lower))
+}
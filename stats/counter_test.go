@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExactCounter(t *testing.T) {
+
+	counter := NewExactCounter()
+	for _, v := range []string{"a", "b", "c", "b", "a", "b", "d"} {
+		counter.Add(v)
+	}
+
+	if counter.Distinct() != 4 {
+		t.Fatal("failed test\n", counter.Distinct())
+	}
+
+	top := counter.Top(3)
+	expect := []ItemCount{{"b", 3}, {"a", 2}, {"c", 1}}
+
+	if !reflect.DeepEqual(top, expect) {
+		t.Fatal("failed test\n", top)
+	}
+}
+
+func TestApproxCounter(t *testing.T) {
+
+	counter := NewApproxCounter()
+	for _, v := range []string{"a", "b", "c", "b", "a", "b", "d"} {
+		counter.Add(v)
+	}
+
+	if counter.Distinct() != 4 {
+		t.Fatal("failed test\n", counter.Distinct())
+	}
+
+	top := counter.Top(3)
+	expect := []ItemCount{{"b", 3}, {"a", 2}, {"c", 1}}
+
+	if !reflect.DeepEqual(top, expect) {
+		t.Fatal("failed test\n", top)
+	}
+}
+
+func TestExactQuantiles(t *testing.T) {
+
+	quantiles := NewExactQuantiles()
+	for _, v := range []float64{4, 1, 3, 2} {
+		quantiles.Add(v)
+	}
+
+	tests := []struct {
+		q      float64
+		expect float64
+	}{
+		{0, 1},
+		{0.25, 1.75},
+		{0.5, 2.5},
+		{1, 4},
+	}
+
+	for _, test := range tests {
+		result := quantiles.Quantile(test.q)
+		if result != test.expect {
+			t.Fatal("failed test\n", test.q, result)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+
+	hll := NewHyperLogLog(14)
+	if hll.Count() != 0 {
+		t.Fatal("failed test\n", hll.Count())
+	}
+
+	for i := 0; i < 100000; i++ {
+		hll.Add(strconv.Itoa(i))
+		hll.Add(strconv.Itoa(i)) // 同じ値は数えない
+	}
+
+	// 誤差は2%以内
+	count := hll.Count()
+	if math.Abs(float64(count-100000))/100000 > 0.02 {
+		t.Fatal("failed test\n", count)
+	}
+}
+
+func TestHyperLogLog_small(t *testing.T) {
+
+	hll := NewHyperLogLog(14)
+	hll.Add("a")
+	hll.Add("b")
+	hll.Add("a")
+	hll.Add("c")
+
+	if hll.Count() != 3 {
+		t.Fatal("failed test\n", hll.Count())
+	}
+}
@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// t-digestによる分位数の推定
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []float64
+	count       float64
+	min         float64
+	max         float64
+}
+
+func NewTDigest(compression float64) *TDigest {
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+func (t *TDigest) Add(value float64) {
+
+	t.buffer = append(t.buffer, value)
+	t.count++
+	t.min = math.Min(t.min, value)
+	t.max = math.Max(t.max, value)
+
+	if len(t.buffer) >= int(t.compression)*10 {
+		t.merge()
+	}
+}
+
+func (t *TDigest) merge() {
+
+	if len(t.buffer) == 0 {
+		return
+	}
+
+	all := t.centroids
+	for _, v := range t.buffer {
+		all = append(all, centroid{mean: v, weight: 1})
+	}
+	t.buffer = t.buffer[:0]
+
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := []centroid{all[0]}
+	cumulative := 0.0
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+
+		// 両端ほどセントロイドの大きさを小さく抑える
+		q := (cumulative + (last.weight+c.weight)/2) / t.count
+		limit := 4 * t.count * q * (1 - q) / t.compression
+
+		if last.weight+c.weight <= math.Max(limit, 1) {
+			last.mean += (c.mean - last.mean) * c.weight / (last.weight + c.weight)
+			last.weight += c.weight
+		} else {
+			cumulative += last.weight
+			merged = append(merged, c)
+		}
+	}
+
+	t.centroids = merged
+}
+
+func (t *TDigest) Count() int {
+	return int(t.count)
+}
+
+// qは0～1で指定
+func (t *TDigest) Quantile(q float64) float64 {
+
+	t.merge()
+
+	if t.count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+
+	// 最小値、各セントロイドの中心、最大値の間を線形補間
+	target := q * t.count
+	cumulative := 0.0
+	prevPosition := 0.0
+	prevMean := t.min
+	for _, c := range t.centroids {
+		position := cumulative + c.weight/2
+		if target < position {
+			return prevMean + (c.mean-prevMean)*(target-prevPosition)/(position-prevPosition)
+		}
+		cumulative += c.weight
+		prevPosition = position
+		prevMean = c.mean
+	}
+
+	return prevMean + (t.max-prevMean)*(target-prevPosition)/(t.count-prevPosition)
+}
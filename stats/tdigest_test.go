@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTDigest(t *testing.T) {
+
+	digest := NewTDigest(100)
+
+	r := rand.New(rand.NewSource(1))
+	for _, i := range r.Perm(100001) {
+		digest.Add(float64(i))
+	}
+
+	if digest.Count() != 100001 {
+		t.Fatal("failed test\n", digest.Count())
+	}
+
+	if digest.Quantile(0) != 0 || digest.Quantile(1) != 100000 {
+		t.Fatal("failed test\n", digest.Quantile(0), digest.Quantile(1))
+	}
+
+	// 誤差は0.5%以内
+	for _, q := range []float64{0.01, 0.25, 0.5, 0.75, 0.99} {
+		result := digest.Quantile(q)
+		if math.Abs(result-q*100000) > 500 {
+			t.Fatal("failed test\n", q, result)
+		}
+	}
+}
+
+func TestTDigest_few(t *testing.T) {
+
+	digest := NewTDigest(100)
+	digest.Add(3)
+	digest.Add(1)
+	digest.Add(2)
+	digest.Add(4)
+
+	if digest.Quantile(0.5) != 2.5 {
+		t.Fatal("failed test\n", digest.Quantile(0.5))
+	}
+}
+
+func TestTDigest_empty(t *testing.T) {
+
+	digest := NewTDigest(100)
+
+	if !math.IsNaN(digest.Quantile(0.5)) {
+		t.Fatal("failed test\n", digest.Quantile(0.5))
+	}
+}
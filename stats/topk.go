@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"container/heap"
+	"sort"
+)
+
+type ItemCount struct {
+	Value string
+	Count int
+}
+
+// Space-Savingアルゴリズムによる頻出値の推定
+// 保持する数(capacity)を超える種類の値が来た場合、最小の値を置き換える
+type TopK struct {
+	capacity int
+	items    map[string]*topKItem
+	heap     topKHeap
+}
+
+type topKItem struct {
+	ItemCount
+	index int
+}
+
+func NewTopK(capacity int) *TopK {
+	return &TopK{
+		capacity: capacity,
+		items:    map[string]*topKItem{},
+	}
+}
+
+func (t *TopK) Add(value string) {
+
+	if item, ok := t.items[value]; ok {
+		item.Count++
+		heap.Fix(&t.heap, item.index)
+		return
+	}
+
+	if len(t.items) < t.capacity {
+		item := &topKItem{ItemCount: ItemCount{Value: value, Count: 1}}
+		t.items[value] = item
+		heap.Push(&t.heap, item)
+		return
+	}
+
+	// 最小のものを置き換え
+	min := t.heap[0]
+	delete(t.items, min.Value)
+	min.Value = value
+	min.Count++
+	t.items[value] = min
+	heap.Fix(&t.heap, min.index)
+}
+
+// 件数の多い順(同じ場合は値の順)にn件返す
+func (t *TopK) Top(n int) []ItemCount {
+
+	items := []ItemCount{}
+	for _, item := range t.items {
+		items = append(items, item.ItemCount)
+	}
+
+	SortItemCounts(items)
+
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+func SortItemCounts(items []ItemCount) {
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Value < items[j].Value
+	})
+}
+
+type topKHeap []*topKItem
+
+func (h topKHeap) Len() int           { return len(h) }
+func (h topKHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h topKHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topKHeap) Push(x any) {
+	item := x.(*topKItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *topKHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+
+	topK := NewTopK(10)
+
+	// 頻出値の間に多数の異なる値が入っても、頻出値は残ること
+	for i := 0; i < 1000; i++ {
+		topK.Add("a")
+		if i%2 == 0 {
+			topK.Add("b")
+		}
+		if i%4 == 0 {
+			topK.Add("c")
+		}
+		topK.Add(strconv.Itoa(i))
+	}
+
+	top := topK.Top(3)
+	values := []string{}
+	for _, item := range top {
+		values = append(values, item.Value)
+	}
+
+	if !reflect.DeepEqual(values, []string{"a", "b", "c"}) {
+		t.Fatal("failed test\n", top)
+	}
+	if top[0].Count != 1000 {
+		t.Fatal("failed test\n", top)
+	}
+}
+
+func TestTopK_less(t *testing.T) {
+
+	topK := NewTopK(10)
+	topK.Add("b")
+	topK.Add("a")
+	topK.Add("b")
+
+	top := topK.Top(5)
+	expect := []ItemCount{{"b", 2}, {"a", 1}}
+
+	if !reflect.DeepEqual(top, expect) {
+		t.Fatal("failed test\n", top)
+	}
+}
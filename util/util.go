@@ -1,5 +1,11 @@
 package util
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 func Remove(strings []string, search string) []string {
 
 	result := []string{}
@@ -10,3 +16,38 @@ func Remove(strings []string, search string) []string {
 	}
 	return result
 }
+
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	// 長いものから判定
+	{"KB", 1024},
+	{"MB", 1024 * 1024},
+	{"GB", 1024 * 1024 * 1024},
+	{"K", 1024},
+	{"M", 1024 * 1024},
+	{"G", 1024 * 1024 * 1024},
+	{"B", 1},
+}
+
+// 100M や 1.5G のような単位付きのサイズをバイト数に (単位は1024倍)
+func ParseByteSize(size string) (int64, error) {
+
+	number := strings.TrimSpace(size)
+	multiplier := int64(1)
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(strings.ToUpper(number), unit.suffix) {
+			number = strings.TrimSpace(number[:len(number)-len(unit.suffix)])
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %s", size)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
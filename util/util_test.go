@@ -30,3 +30,39 @@ func TestRemove_multi(t *testing.T) {
 		t.Fatal("failed test\n", removed)
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+
+	tests := []struct {
+		size   string
+		expect int64
+	}{
+		{"100", 100},
+		{"100B", 100},
+		{"2K", 2048},
+		{"2kb", 2048},
+		{"100M", 100 * 1024 * 1024},
+		{"1.5G", 1536 * 1024 * 1024},
+		{" 10 MB ", 10 * 1024 * 1024},
+	}
+
+	for _, test := range tests {
+		result, err := ParseByteSize(test.size)
+		if err != nil {
+			t.Fatal("failed test\n", err)
+		}
+		if result != test.expect {
+			t.Fatal("failed test\n", test.size, result)
+		}
+	}
+}
+
+func TestParseByteSize_invalid(t *testing.T) {
+
+	for _, size := range []string{"", "M", "abc", "-1", "10T"} {
+		_, err := ParseByteSize(size)
+		if err == nil || err.Error() != "invalid size "+size {
+			t.Fatal("failed test\n", size, err)
+		}
+	}
+}